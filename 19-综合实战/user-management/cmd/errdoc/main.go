@@ -0,0 +1,49 @@
+// cmd/errdoc/main.go - 根据 apperr 注册表生成错误码文档
+//
+// 用法（在项目根目录）:
+//
+//	go generate ./internal/apperr
+//	go run ./cmd/errdoc -o docs/error-codes.md
+//
+// 📌 文档由注册表生成，不要手动编辑
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"user-management/internal/apperr"
+)
+
+func main() {
+	output := flag.String("o", "", "输出文件，留空时输出到标准输出")
+	flag.Parse()
+
+	var buf bytes.Buffer
+	buf.WriteString("# 错误码\n\n")
+	buf.WriteString("<!-- 由 cmd/errdoc 根据 internal/apperr 生成，请勿手动编辑 -->\n\n")
+	buf.WriteString("错误响应格式:\n\n")
+	buf.WriteString("```json\n{\"code\": 40001, \"message\": \"参数错误\", \"details\": [{\"field\": \"email\", \"rule\": \"email\", \"message\": \"email 格式不正确\"}]}\n```\n\n")
+	buf.WriteString("业务码 = HTTP 状态码 × 100 + 序号；message 按 Accept-Language 返回中文（默认）或英文。\n\n")
+	buf.WriteString("| 业务码 | HTTP 状态 | i18n key | 中文 | English |\n")
+	buf.WriteString("|---|---|---|---|---|\n")
+	for _, e := range apperr.All() {
+		fmt.Fprintf(&buf, "| %d | %d %s | `%s` | %s | %s |\n",
+			e.Code, e.Status, http.StatusText(e.Status), e.Key, e.Message, e.Localize(apperr.LangEN).Message)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,137 @@
+// cmd/server/admin.go - 管理员账号子命令
+//
+// 用法:
+//
+//	ADMIN_PASSWORD=... go run ./cmd/server admin create --username alice --email alice@example.com
+//	echo '...' | go run ./cmd/server admin create --username alice --email alice@example.com
+//	go run ./cmd/server admin promote --username bob
+//	go run ./cmd/server admin demote --username bob
+//
+// 📌 密码不出现在命令行参数中（会留在 shell 历史和进程列表里）
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+	"user-management/internal/config"
+	"user-management/internal/database"
+	"user-management/internal/migrate"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/service"
+)
+
+func runAdmin(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal("用法: admin create|promote|demote --username <name> [--email <email>]")
+	}
+
+	fs := flag.NewFlagSet("admin "+args[0], flag.ExitOnError)
+	username := fs.String("username", "", "用户名")
+	email := fs.String("email", "", "邮箱（create 必填）")
+	mustChange := fs.Bool("must-change-password", false, "首次登录必须修改密码（create）")
+	fs.Parse(args[1:])
+	if *username == "" {
+		log.Fatal("缺少 --username")
+	}
+
+	adminService, err := newAdminService(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	actor := &model.Actor{Username: "cli"}
+
+	var user *model.UserResponse
+	switch args[0] {
+	case "create":
+		if _, err := mail.ParseAddress(*email); err != nil {
+			log.Fatal("缺少或无效的 --email")
+		}
+		pw, err := readPassword()
+		if err != nil {
+			log.Fatal(err)
+		}
+		user, err = adminService.CreateAdmin(ctx, &model.CreateAdminRequest{
+			Username:           *username,
+			Email:              *email,
+			Password:           pw,
+			MustChangePassword: *mustChange,
+		}, actor)
+		if err != nil {
+			log.Fatalf("创建管理员失败: %v", err)
+		}
+		fmt.Printf("✓ 已创建管理员 %s (id=%d)\n", user.Username, user.ID)
+	case "promote":
+		user, err = adminService.Promote(ctx, *username, actor)
+		if err != nil {
+			log.Fatalf("设置管理员失败: %v", err)
+		}
+		fmt.Printf("✓ %s 的角色: %s\n", user.Username, strings.Join(user.Roles, ", "))
+	case "demote":
+		user, err = adminService.Demote(ctx, *username, actor)
+		if err != nil {
+			log.Fatalf("取消管理员失败: %v", err)
+		}
+		fmt.Printf("✓ %s 的角色: %s\n", user.Username, strings.Join(user.Roles, ", "))
+	default:
+		log.Fatalf("未知的 admin 子命令: %s", args[0])
+	}
+}
+
+// newAdminService 连接数据库并初始化内置角色
+func newAdminService(cfg *config.Config) (service.AdminService, error) {
+	db, err := database.Open(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		return nil, err
+	}
+
+	policy, err := password.NewPolicy(&cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+	hasher, err := password.NewHasher(&cfg.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	rbacRepo := repository.NewRBACRepository(db)
+	txManager := repository.NewTxManager(db)
+	if err := service.NewRBACService(rbacRepo, userRepo, txManager).SeedDefaults(context.Background()); err != nil {
+		return nil, fmt.Errorf("初始化角色权限失败: %w", err)
+	}
+	return service.NewAdminService(userRepo, rbacRepo, txManager, policy, hasher), nil
+}
+
+// readPassword 优先读取环境变量 ADMIN_PASSWORD，否则从标准输入读取一行
+func readPassword() (string, error) {
+	if pw := os.Getenv("ADMIN_PASSWORD"); pw != "" {
+		return pw, nil
+	}
+
+	fmt.Fprint(os.Stderr, "密码: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	pw := strings.TrimRight(line, "\r\n")
+	if pw == "" {
+		if err != nil {
+			return "", fmt.Errorf("读取密码失败: %w", err)
+		}
+		return "", fmt.Errorf("密码不能为空")
+	}
+	return pw, nil
+}
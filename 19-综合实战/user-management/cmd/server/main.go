@@ -7,8 +7,8 @@
 //   - GORM: ORM 框架
 //   - Viper: 配置管理
 //   - Zap: 结构化日志
-//   - JWT: 认证
-//   - bcrypt: 密码哈希
+//   - JWT: 认证 (HS256 / RS256 / EdDSA，多密钥轮换)
+//   - bcrypt / argon2id: 密码哈希
 //
 // 项目结构:
 //   ├── cmd/server/          # 入口
@@ -24,29 +24,81 @@
 // API:
 //   POST /api/register       - 注册
 //   POST /api/login          - 登录
+//   POST /api/login/mfa      - 两步登录第二步（mfa_token + 验证码）
+//   POST /api/token/refresh  - 刷新 token
+//   POST /api/logout         - 退出登录 (需认证)
+//   POST /api/password/forgot - 忘记密码，发送重置邮件
+//   POST /api/password/reset  - 使用邮件令牌重置密码
+//   GET  /api/verify-email?token= - 验证邮箱
+//   POST /api/verify-email/resend - 重新发送验证邮件
 //   GET  /api/profile        - 获取个人信息 (需认证)
 //   PUT  /api/profile        - 更新个人信息 (需认证)
 //   PUT  /api/password       - 修改密码 (需认证)
-//   GET  /api/admin/users    - 用户列表 (需管理员)
-//   DELETE /api/admin/users/:id - 删除用户 (需管理员)
+//   POST /api/mfa/totp/setup   - 生成 TOTP 密钥和恢复码 (需认证)
+//   POST /api/mfa/totp/enable  - 验证码确认开启两步验证 (需认证)
+//   POST /api/mfa/totp/disable - 关闭两步验证 (需认证)
+//   GET  /api/admin/users    - 用户列表 (需 user:read)
+//                              ?keyword=&role=&status=&created_from=&created_to=&sort_by=&sort_order=
+//                              ?pagination=cursor&cursor=  (游标分页)
+//   DELETE /api/admin/users/:id - 删除用户，软删除 (需 user:delete)
+//   POST /api/admin/users/:id/restore - 恢复用户 (需 user:restore)
+//   POST /api/admin/users/purge       - 清除超过保留期的已删除用户 (需 user:purge)
+//   POST /api/admin/users/:id/disable - 禁用用户 (需 user:status)
+//   POST /api/admin/users/:id/enable  - 启用用户 (需 user:status)
+//   POST /api/admin/users/:id/lock    - 锁定用户 (需 user:status)
+//   POST /api/admin/users/:id/unlock  - 解除锁定 (需 user:status)
+//   POST /api/admin/users/:id/mfa/reset - 重置两步验证 (需 user:mfa)
+//   GET/POST /api/admin/roles           - 角色列表/创建 (需 role:read / role:manage)
+//   PUT/DELETE /api/admin/roles/:id     - 更新/删除角色 (需 role:manage)
+//   GET/POST /api/admin/permissions     - 权限列表/创建 (需 role:read / role:manage)
+//   PUT  /api/admin/users/:id/roles     - 分配用户角色 (需 role:manage)
+//   GET  /api/admin/audit               - 审计日志 (需 audit:read)
+//                                         ?actor_id=&target_id=&action=&from=&to=&format=csv|json
+//   GET/PUT /api/admin/log-level        - 查看/修改运行时日志级别 (需 system:log)
+//   GET  /.well-known/jwks.json         - JWT 公钥集合 (JWKS)
+//   GET  /metrics                       - Prometheus 指标
+//   GET  /livez                         - 存活检查
+//   GET  /readyz                        - 就绪检查（数据库、迁移、磁盘空间），退出时返回 503
+//
+// 数据库迁移:
+//   server migrate up            - 执行全部待执行的迁移
+//   server migrate down [n]      - 回滚最近 n 个迁移（默认 1）
+//   server migrate status        - 查看迁移状态
+//   server migrate create <name> - 生成新的迁移文件
+//
+// 管理员账号（密码从环境变量 ADMIN_PASSWORD 或标准输入读取）:
+//   server admin create --username <name> --email <email>
+//   server admin promote --username <name>
+//   server admin demote --username <name>
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"user-management/internal/config"
+	"user-management/internal/database"
 	"user-management/internal/handler"
+	"user-management/internal/health"
+	"user-management/internal/jwtkeys"
+	"user-management/internal/logging"
+	"user-management/internal/mailer"
+	"user-management/internal/metrics"
 	"user-management/internal/middleware"
-	"user-management/internal/model"
+	"user-management/internal/migrate"
+	"user-management/internal/password"
+	"user-management/internal/redact"
 	"user-management/internal/repository"
 	"user-management/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -57,23 +109,113 @@ func main() {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 
+	// 子命令: migrate up|down|status|create, admin create|promote|demote
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrate(&cfg.Database, os.Args[2:])
+			return
+		case "admin":
+			runAdmin(cfg, os.Args[2:])
+			return
+		}
+	}
+
 	// 2. 初始化日志
-	logger := initLogger(cfg.Log.Level)
+	logger, logLevel, err := logging.New(&cfg.Log)
+	if err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
 	defer logger.Sync()
+	// 📌 ctx 中没有请求级 logger 时（启动阶段、GORM 后台日志）使用全局 logger
+	zap.ReplaceGlobals(logger)
 
 	// 3. 初始化数据库
-	db, err := initDB(cfg.Database.DSN)
+	db, migrator, err := initDB(&cfg.Database, logger)
 	if err != nil {
 		logger.Fatal("数据库初始化失败", zap.Error(err))
 	}
+	appMetrics := metrics.New()
+	if err := appMetrics.RegisterDB(db); err != nil {
+		logger.Fatal("数据库指标注册失败", zap.Error(err))
+	}
 
-	// 创建管理员账号
-	createAdminUser(db)
+	passwordPolicy, err := password.NewPolicy(&cfg.Password)
+	if err != nil {
+		logger.Fatal("密码策略初始化失败", zap.Error(err))
+	}
+	passwordHasher, err := password.NewHasher(&cfg.Hash)
+	if err != nil {
+		logger.Fatal("密码哈希初始化失败", zap.Error(err))
+	}
+	syncMailer, err := mailer.New(&cfg.Mail, logger)
+	if err != nil {
+		logger.Fatal("邮件初始化失败", zap.Error(err))
+	}
+	mailSender := mailer.NewAsyncMailer(syncMailer, logger)
 
 	// 4. 依赖注入
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, &cfg.JWT)
-	userHandler := handler.NewUserHandler(userService)
+	sessionRepo := repository.NewSessionRepository(db)
+	rbacRepo := repository.NewRBACRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	txManager := repository.NewTxManager(db)
+	keySet, err := jwtkeys.Load(&cfg.JWT)
+	if err != nil {
+		logger.Fatal("JWT 密钥加载失败", zap.Error(err))
+	}
+	if err := jwtkeys.CheckProduction(&cfg.JWT); err != nil {
+		if cfg.Server.Mode == "release" {
+			logger.Fatal("JWT 密钥不安全", zap.Error(err))
+		}
+		logger.Warn("JWT 密钥不安全，仅限本地开发", zap.Error(err))
+	}
+	tokenService := service.NewTokenService(sessionRepo, userRepo, keySet, &cfg.JWT)
+	loginGuard := service.NewLoginGuard(loginAttemptRepo, &cfg.Login, appMetrics)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, txManager, loginAttemptRepo, loginGuard,
+		passwordPolicy, passwordHasher, mailSender, cfg.Server.BaseURL, &cfg.Reset, &cfg.Verify)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, txManager, passwordHasher, loginGuard, &cfg.MFA, time.Now)
+	userService := service.NewUserService(userRepo, rbacRepo, auditRepo, txManager, tokenService, loginGuard,
+		passwordPolicy, passwordHasher, accountService, mfaService, &cfg.User, &cfg.Verify, appMetrics)
+	rbacService := service.NewRBACService(rbacRepo, userRepo, txManager)
+	adminService := service.NewAdminService(userRepo, rbacRepo, txManager, passwordPolicy, passwordHasher)
+	auditService := service.NewAuditService(auditRepo)
+	userHandler := handler.NewUserHandler(userService, tokenService)
+	roleHandler := handler.NewRoleHandler(rbacService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	jwksHandler := handler.NewJWKSHandler(keySet)
+	auditHandler := handler.NewAuditHandler(auditService)
+	logHandler := handler.NewLogHandler(logLevel)
+
+	// 就绪检查项
+	probes := health.New(time.Duration(cfg.Health.CheckTimeoutSeconds) * time.Second)
+	probes.Register("database", health.DBPing(db))
+	probes.Register("migrations", health.Migrations(migrator))
+	if dir := database.SQLiteDataDir(&cfg.Database); dir != "" && cfg.Health.MinFreeDiskMB > 0 {
+		probes.Register("disk", health.DiskSpace(dir, uint64(cfg.Health.MinFreeDiskMB)<<20))
+	}
+	healthHandler := handler.NewHealthHandler(probes)
+
+	// 初始化内置角色权限；按配置创建初始管理员
+	if err := rbacService.SeedDefaults(context.Background()); err != nil {
+		logger.Fatal("初始化角色权限失败", zap.Error(err))
+	}
+	admin, err := adminService.Bootstrap(context.Background(), &cfg.Admin)
+	if err != nil {
+		logger.Fatal("创建初始管理员失败", zap.Error(err))
+	}
+	if admin != nil {
+		logger.Info("已创建初始管理员，首次登录需修改密码", zap.String("username", admin.Username))
+	}
+
+	// 定时清除超过保留期的软删除用户
+	if cfg.User.PurgeIntervalHours > 0 {
+		go runPurgeJob(userService, time.Duration(cfg.User.PurgeIntervalHours)*time.Hour, logger)
+	}
 
 	// 5. 设置 Gin
 	if cfg.Server.Mode == "release" {
@@ -81,19 +223,36 @@ func main() {
 	}
 
 	r := gin.New()
-	r.Use(middleware.RecoveryMiddleware(logger))
-	r.Use(middleware.LoggerMiddleware(logger))
+	r.Use(middleware.RequestIDMiddleware(logger))
+	r.Use(appMetrics.Middleware())
+	r.Use(middleware.RecoveryMiddleware())
+	r.Use(middleware.LoggerMiddleware(redact.New(&cfg.Log.Redact, cfg.Log.Body.MaxBytes), cfg.Log.Body.Enabled))
+	r.Use(middleware.ErrorMiddleware())
 
 	// 6. 注册路由
 	api := r.Group("/api")
-	authMiddleware := middleware.AuthMiddleware(&cfg.JWT)
-	adminMiddleware := middleware.AdminMiddleware()
-	userHandler.RegisterRoutes(api, authMiddleware, adminMiddleware)
+	authMiddleware := middleware.AuthMiddleware(tokenService)
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// 权限来源: claims 读取 token 声明，db 每次实时查库
+	var permissionResolver middleware.PermissionResolver
+	if cfg.RBAC.PermissionSource == "db" {
+		permissionResolver = rbacService
+	}
+	requirePermission := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(permissionResolver, permission)
+	}
+	userHandler.RegisterRoutes(api, authMiddleware, requirePermission)
+	roleHandler.RegisterRoutes(api, authMiddleware, requirePermission)
+	accountHandler.RegisterRoutes(api)
+	mfaHandler.RegisterRoutes(api, authMiddleware, requirePermission)
+	auditHandler.RegisterRoutes(api, authMiddleware, requirePermission)
+	logHandler.RegisterRoutes(api, authMiddleware, requirePermission)
+
+	// 存活 / 就绪检查
+	healthHandler.RegisterRoutes(r)
+	r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	jwksHandler.RegisterRoutes(r)
+	r.NoRoute(middleware.NotFound)
 
 	// 7. 启动服务
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -101,72 +260,78 @@ func main() {
 
 	printUsage(cfg.Server.Port)
 
-	if err := r.Run(addr); err != nil {
-		logger.Fatal("服务器启动失败", zap.Error(err))
-	}
-}
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("服务器启动失败", zap.Error(err))
+		}
+	}()
+
+	// 8. 优雅关闭
+	// 📌 先标记未就绪，等待负载均衡摘除实例，再停止接收新连接并等待进行中的请求完成
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	logger.Info("收到退出信号，开始优雅关闭", zap.String("signal", sig.String()))
+
+	probes.SetShuttingDown()
+	time.Sleep(time.Duration(cfg.Server.ShutdownDelaySeconds) * time.Second)
 
-func initLogger(level string) *zap.Logger {
-	var zapLevel zapcore.Level
-	switch level {
-	case "debug":
-		zapLevel = zapcore.DebugLevel
-	case "info":
-		zapLevel = zapcore.InfoLevel
-	case "warn":
-		zapLevel = zapcore.WarnLevel
-	case "error":
-		zapLevel = zapcore.ErrorLevel
-	default:
-		zapLevel = zapcore.InfoLevel
-	}
-
-	config := zap.Config{
-		Level:            zap.NewAtomicLevelAt(zapLevel),
-		Development:      true,
-		Encoding:         "console",
-		EncoderConfig:    zap.NewDevelopmentEncoderConfig(),
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
-	}
-
-	logger, _ := config.Build()
-	return logger
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("优雅关闭超时，强制退出", zap.Error(err))
+	}
+	// 请求处理完后再等待后台邮件发送完成
+	if err := mailSender.Wait(ctx); err != nil {
+		logger.Error("等待邮件发送超时，部分邮件可能未发出", zap.Error(err))
+	}
+	logger.Info("服务器已退出")
 }
 
-func initDB(dsn string) (*gorm.DB, error) {
-	// 确保数据目录存在
-	os.MkdirAll("./data", 0755)
+func initDB(cfg *config.DatabaseConfig, logger *zap.Logger) (*gorm.DB, *migrate.Migrator, error) {
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	migrator, err := migrate.New(db)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	ctx := context.Background()
 
-	// 自动迁移
-	if err := db.AutoMigrate(&model.User{}); err != nil {
-		return nil, err
+	// 📌 auto_migrate 开启时启动即执行迁移（内存数据库/CI），否则结构落后时拒绝启动
+	if cfg.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Info("已执行数据库迁移", zap.Int64("version", m.Version), zap.String("name", m.Name))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := migrator.Check(ctx); err != nil {
+		return nil, nil, err
 	}
 
-	return db, nil
+	return db, migrator, nil
 }
 
-func createAdminUser(db *gorm.DB) {
-	var count int64
-	db.Model(&model.User{}).Where("username = ?", "admin").Count(&count)
-	if count > 0 {
-		return
-	}
+func runPurgeJob(userService service.UserService, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
-	admin := &model.User{
-		Username: "admin",
-		Email:    "admin@example.com",
-		Password: string(hashedPassword),
-		Role:     "admin",
+	for range ticker.C {
+		purged, err := userService.PurgeDeletedUsers(context.Background(), nil)
+		if err != nil {
+			logger.Error("清除已删除用户失败", zap.Error(err))
+			continue
+		}
+		if purged > 0 {
+			logger.Info("已清除过期的软删除用户", zap.Int64("count", purged))
+		}
 	}
-	db.Create(admin)
-	fmt.Println("✓ 创建管理员账号: admin / admin123")
 }
 
 func printUsage(port int) {
@@ -174,12 +339,15 @@ func printUsage(port int) {
 	fmt.Printf("服务地址: http://localhost:%d\n\n", port)
 	fmt.Println("测试命令:")
 	fmt.Println("  # 注册")
-	fmt.Printf("  curl -X POST http://localhost:%d/api/register -H \"Content-Type: application/json\" -d '{\"username\":\"tom\",\"email\":\"tom@example.com\",\"password\":\"123456\"}'\n\n", port)
+	fmt.Printf("  curl -X POST http://localhost:%d/api/register -H \"Content-Type: application/json\" -d '{\"username\":\"tom\",\"email\":\"tom@example.com\",\"password\":\"Tom@2024!pw\"}'\n\n", port)
 	fmt.Println("  # 登录")
-	fmt.Printf("  curl -X POST http://localhost:%d/api/login -H \"Content-Type: application/json\" -d '{\"username\":\"admin\",\"password\":\"admin123\"}'\n\n", port)
+	fmt.Printf("  curl -X POST http://localhost:%d/api/login -H \"Content-Type: application/json\" -d '{\"username\":\"tom\",\"password\":\"Tom@2024!pw\"}'\n\n", port)
+	fmt.Println("  # 刷新 token (旧 refresh token 随即失效)")
+	fmt.Printf("  curl -X POST http://localhost:%d/api/token/refresh -H \"Content-Type: application/json\" -d '{\"refresh_token\":\"<refresh_token>\"}'\n\n", port)
 	fmt.Println("  # 获取个人信息 (需要 token)")
 	fmt.Printf("  curl http://localhost:%d/api/profile -H \"Authorization: Bearer <token>\"\n\n", port)
 	fmt.Println("  # 用户列表 (需要管理员 token)")
 	fmt.Printf("  curl http://localhost:%d/api/admin/users -H \"Authorization: Bearer <admin_token>\"\n", port)
-	fmt.Println("==========================================\n")
+	fmt.Println("==========================================")
+	fmt.Println()
 }
@@ -0,0 +1,100 @@
+// cmd/server/migrate.go - 数据库迁移子命令
+//
+// 用法:
+//
+//	go run ./cmd/server migrate up
+//	go run ./cmd/server migrate down [n]
+//	go run ./cmd/server migrate status
+//	go run ./cmd/server migrate create <name> [dir]
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"user-management/internal/config"
+	"user-management/internal/database"
+	"user-management/internal/migrate"
+)
+
+func runMigrate(cfg *config.DatabaseConfig, args []string) {
+	if len(args) == 0 {
+		log.Fatal("用法: migrate up|down [n]|status|create <name> [dir]")
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("用法: migrate create <name> [dir]")
+		}
+		dir := migrate.DefaultDir
+		if len(args) > 2 {
+			dir = args[2]
+		}
+		paths, err := migrate.Create(dir, args[1])
+		for _, path := range paths {
+			fmt.Println("✓ 创建", path)
+		}
+		if err != nil {
+			log.Fatalf("创建迁移文件失败: %v", err)
+		}
+		return
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("加载迁移失败: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("✓ 已执行 %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatal("回滚步数必须是正整数")
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("✓ 已回滚 %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			appliedAt := "待执行"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		log.Fatalf("未知的 migrate 子命令: %s", args[0])
+	}
+}
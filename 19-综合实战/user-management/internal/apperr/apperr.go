@@ -0,0 +1,123 @@
+// internal/apperr/apperr.go - 应用错误注册表
+//
+// 📌 每个业务错误在 codes.go 中注册一次: 业务码 + HTTP 状态码 + i18n key + 默认（中文）文案
+// 📌 业务码 = HTTP 状态码 * 100 + 两位序号，发布后不再变更，客户端可据此分支处理
+// 📌 service/repository 的哨兵错误直接引用注册表中的值，errors.Is 照常可用
+// 📌 handler/middleware 只需 c.Error(err)，由 middleware.ErrorMiddleware 统一渲染
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AppError 应用错误
+type AppError struct {
+	Code    int          // 业务码，全局唯一
+	Status  int          // HTTP 状态码
+	Key     string       // i18n 文案 key
+	Message string       // 默认文案（中文）
+	Details []FieldError // 可选的字段级详情
+	Err     error        // 底层错误，仅记录日志，不返回给客户端
+}
+
+// FieldError 字段级错误详情
+// 📌 Rule 为校验规则（binding tag 或密码策略规则），Param 为规则参数，客户端可据此自行组织文案
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// DetailProvider 可以提供字段详情的错误（如 password.PolicyError）
+type DetailProvider interface {
+	FieldErrors() []FieldError
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is 业务码相同即视为同一错误，Wrap/WithDetails 产生的副本仍能匹配注册的哨兵
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 返回附带底层错误的副本
+func (e *AppError) Wrap(err error) *AppError {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// WithDetails 返回附带字段详情的副本
+func (e *AppError) WithDetails(details ...FieldError) *AppError {
+	cp := *e
+	cp.Details = append(append([]FieldError(nil), e.Details...), details...)
+	return &cp
+}
+
+var registry = make(map[int]*AppError)
+
+// New 注册一个应用错误，业务码重复时 panic
+func New(code, status int, key, message string) *AppError {
+	if _, ok := registry[code]; ok {
+		panic(fmt.Sprintf("apperr: 业务码 %d 重复注册", code))
+	}
+	e := &AppError{Code: code, Status: status, Key: key, Message: message}
+	registry[code] = e
+	return e
+}
+
+// Lookup 按业务码查找已注册的错误
+func Lookup(code int) (*AppError, bool) {
+	e, ok := registry[code]
+	return e, ok
+}
+
+// All 返回全部已注册的错误，按业务码排序
+func All() []*AppError {
+	list := make([]*AppError, 0, len(registry))
+	for _, e := range registry {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// From 将任意错误转换为 AppError
+// 📌 未注册的错误一律视为 Internal，原始错误保留在 Err 中用于日志
+func From(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *AppError
+	switch {
+	case errors.As(err, &appErr):
+	// 📌 SQL 超时（database.query_timeout_seconds）或客户端断开导致数据库操作被取消
+	case errors.Is(err, context.DeadlineExceeded):
+		appErr = RequestTimeout
+	case errors.Is(err, context.Canceled):
+		appErr = RequestCanceled
+	default:
+		return Internal.Wrap(err)
+	}
+
+	var provider DetailProvider
+	if len(appErr.Details) == 0 && errors.As(err, &provider) {
+		appErr = appErr.WithDetails(provider.FieldErrors()...)
+	}
+	return appErr
+}
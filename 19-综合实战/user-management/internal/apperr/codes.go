@@ -0,0 +1,75 @@
+// internal/apperr/codes.go - 业务错误码
+//
+// 📌 新增错误只在此处注册，然后执行 go generate ./... 更新 docs/error-codes.md
+// 📌 已发布的业务码不能修改或复用
+package apperr
+
+import "net/http"
+
+//go:generate go run ../../cmd/errdoc -o ../../docs/error-codes.md
+
+// 通用
+var (
+	InvalidParams   = New(40001, http.StatusBadRequest, "common.invalid_params", "参数错误")
+	InvalidID       = New(40002, http.StatusBadRequest, "common.invalid_id", "无效的ID")
+	RouteNotFound   = New(40401, http.StatusNotFound, "common.route_not_found", "接口不存在")
+	Internal        = New(50001, http.StatusInternalServerError, "common.internal", "服务器错误")
+	RequestCanceled = New(50301, http.StatusServiceUnavailable, "common.request_canceled", "请求已取消")
+	RequestTimeout  = New(50401, http.StatusGatewayTimeout, "common.request_timeout", "请求超时，请稍后重试")
+)
+
+// 认证与会话
+var (
+	MissingToken        = New(40101, http.StatusUnauthorized, "auth.missing_token", "缺少认证头")
+	MalformedToken      = New(40102, http.StatusUnauthorized, "auth.malformed_token", "认证格式错误")
+	InvalidToken        = New(40103, http.StatusUnauthorized, "auth.invalid_token", "token 无效或已过期")
+	InvalidCredentials  = New(40104, http.StatusUnauthorized, "auth.invalid_credentials", "用户名或密码错误")
+	InvalidRefreshToken = New(40105, http.StatusUnauthorized, "auth.invalid_refresh_token", "refresh token 无效或已过期")
+	SessionNotFound     = New(40106, http.StatusUnauthorized, "auth.session_not_found", "会话不存在")
+	InvalidMFACode      = New(40107, http.StatusUnauthorized, "mfa.invalid_code", "验证码错误")
+	PermissionDenied    = New(40305, http.StatusForbidden, "auth.permission_denied", "缺少权限")
+)
+
+// 账号状态与登录防护
+var (
+	AccountPending          = New(40301, http.StatusForbidden, "account.pending", "账号尚未激活")
+	AccountDisabled         = New(40302, http.StatusForbidden, "account.disabled", "账号已被禁用")
+	EmailNotVerified        = New(40303, http.StatusForbidden, "account.email_not_verified", "邮箱尚未验证")
+	PasswordChangeRequired  = New(40304, http.StatusForbidden, "account.password_change_required", "请先修改初始密码")
+	AccountLocked           = New(42301, http.StatusLocked, "account.locked", "账号已被锁定")
+	LoginTemporarilyLocked  = New(42302, http.StatusLocked, "account.login_temporarily_locked", "登录失败次数过多，账号暂时锁定")
+	TooManyLoginAttempts    = New(42901, http.StatusTooManyRequests, "account.too_many_login_attempts", "登录尝试过于频繁，请稍后再试")
+	VerificationRateLimited = New(42902, http.StatusTooManyRequests, "account.verification_rate_limited", "验证邮件发送过于频繁，请稍后再试")
+	ResetRateLimited        = New(42903, http.StatusTooManyRequests, "account.reset_rate_limited", "重置邮件发送过于频繁，请稍后再试")
+)
+
+// 用户与账号
+var (
+	PasswordPolicy     = New(40003, http.StatusBadRequest, "password.policy_violation", "密码不符合安全策略")
+	WrongPassword      = New(40004, http.StatusBadRequest, "password.wrong_old_password", "原密码错误")
+	InvalidResetToken  = New(40005, http.StatusBadRequest, "account.invalid_reset_token", "重置链接无效或已过期")
+	InvalidVerifyToken = New(40006, http.StatusBadRequest, "account.invalid_verify_token", "验证链接无效或已过期")
+	InvalidCursor      = New(40007, http.StatusBadRequest, "pagination.invalid_cursor", "无效的游标")
+	CursorSortInvalid  = New(40008, http.StatusBadRequest, "pagination.cursor_sort_invalid", "游标分页仅支持按 created_at 排序")
+	UserNotFound       = New(40402, http.StatusNotFound, "user.not_found", "用户不存在")
+	UsernameExists     = New(40901, http.StatusConflict, "user.username_exists", "用户名已存在")
+	EmailExists        = New(40902, http.StatusConflict, "user.email_exists", "邮箱已存在")
+	LastAdmin          = New(40906, http.StatusConflict, "user.last_admin", "不能移除最后一个管理员")
+)
+
+// 角色与权限
+var (
+	PermissionNotFound = New(40009, http.StatusBadRequest, "rbac.permission_not_found", "权限不存在")
+	BuiltinRole        = New(40010, http.StatusBadRequest, "rbac.builtin_role", "内置角色不允许删除")
+	AdminPermissions   = New(40013, http.StatusBadRequest, "rbac.admin_permissions_required", "管理员角色必须保留全部内置权限")
+	RoleNotFound       = New(40403, http.StatusNotFound, "rbac.role_not_found", "角色不存在")
+	RoleExists         = New(40903, http.StatusConflict, "rbac.role_exists", "角色已存在")
+	PermissionExists   = New(40904, http.StatusConflict, "rbac.permission_exists", "权限已存在")
+)
+
+// 两步验证
+var (
+	MFANotEnabled     = New(40011, http.StatusBadRequest, "mfa.not_enabled", "两步验证未开启")
+	MFAReauthFailed   = New(40012, http.StatusBadRequest, "mfa.reauth_failed", "密码错误，无法确认身份")
+	MFAAlreadyEnabled = New(40905, http.StatusConflict, "mfa.already_enabled", "两步验证已开启")
+)
@@ -0,0 +1,148 @@
+// internal/apperr/i18n.go - 错误文案多语言
+//
+// 📌 注册时的 Message 即默认语言（zh）文案，其他语言按 Key 查表，缺失时回退到默认文案
+// 📌 字段详情按 Rule 查模板，{field} 为字段名，{param} 为规则参数
+package apperr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LangZH = "zh"
+	LangEN = "en"
+
+	// DefaultLang 注册文案所用的语言
+	DefaultLang = LangZH
+)
+
+var messages = map[string]map[string]string{
+	LangEN: {
+		"common.invalid_params":             "Invalid parameters",
+		"common.invalid_id":                 "Invalid ID",
+		"common.route_not_found":            "Route not found",
+		"common.internal":                   "Internal server error",
+		"common.request_canceled":           "Request canceled",
+		"common.request_timeout":            "Request timed out, please try again later",
+		"auth.missing_token":                "Missing Authorization header",
+		"auth.malformed_token":              "Malformed Authorization header",
+		"auth.invalid_token":                "Token is invalid or expired",
+		"auth.invalid_credentials":          "Invalid username or password",
+		"auth.invalid_refresh_token":        "Refresh token is invalid or expired",
+		"auth.session_not_found":            "Session not found",
+		"auth.permission_denied":            "Permission denied",
+		"mfa.invalid_code":                  "Invalid verification code",
+		"mfa.not_enabled":                   "Two-factor authentication is not enabled",
+		"mfa.already_enabled":               "Two-factor authentication is already enabled",
+		"mfa.reauth_failed":                 "Incorrect password, unable to confirm your identity",
+		"account.pending":                   "Account is not activated yet",
+		"account.disabled":                  "Account is disabled",
+		"account.email_not_verified":        "Email is not verified",
+		"account.password_change_required":  "Please change your initial password first",
+		"account.locked":                    "Account is locked",
+		"account.login_temporarily_locked":  "Too many failed logins, account temporarily locked",
+		"account.too_many_login_attempts":   "Too many login attempts, please try again later",
+		"account.verification_rate_limited": "Verification emails are sent too frequently, please try again later",
+		"account.reset_rate_limited":        "Password reset emails are sent too frequently, please try again later",
+		"account.invalid_reset_token":       "Reset link is invalid or expired",
+		"account.invalid_verify_token":      "Verification link is invalid or expired",
+		"password.policy_violation":         "Password does not meet the security policy",
+		"password.wrong_old_password":       "Old password is incorrect",
+		"pagination.invalid_cursor":         "Invalid cursor",
+		"pagination.cursor_sort_invalid":    "Cursor pagination only supports sorting by created_at",
+		"user.not_found":                    "User not found",
+		"user.username_exists":              "Username already exists",
+		"user.email_exists":                 "Email already exists",
+		"user.last_admin":                   "Cannot remove the last administrator",
+		"rbac.permission_not_found":         "Permission not found",
+		"rbac.builtin_role":                 "Built-in roles cannot be deleted",
+		"rbac.admin_permissions_required":   "The admin role must keep all built-in permissions",
+		"rbac.role_not_found":               "Role not found",
+		"rbac.role_exists":                  "Role already exists",
+		"rbac.permission_exists":            "Permission already exists",
+	},
+}
+
+var fieldMessages = map[string]map[string]string{
+	LangZH: {
+		"required":        "{field} 不能为空",
+		"email":           "{field} 格式不正确",
+		"min":             "{field} 长度不能小于 {param}",
+		"max":             "{field} 长度不能大于 {param}",
+		"oneof":           "{field} 必须是 [{param}] 之一",
+		"type":            "{field} 类型应为 {param}",
+		"permission":      "缺少权限 {param}",
+		"cursor_mismatch": "游标与当前查询的 {param} 不一致，请去掉 cursor 从第一页开始",
+	},
+	LangEN: {
+		"required":        "{field} is required",
+		"email":           "{field} must be a valid email",
+		"min":             "{field} must be at least {param} characters",
+		"max":             "{field} must be at most {param} characters",
+		"oneof":           "{field} must be one of [{param}]",
+		"type":            "{field} must be of type {param}",
+		"permission":      "missing permission {param}",
+		"cursor_mismatch": "cursor does not match the current {param}; restart from the first page without cursor",
+		"min_length":      "password must be at least {param} characters",
+		"max_bytes":       "password must not exceed {param} bytes",
+		"upper":           "password must contain an uppercase letter",
+		"lower":           "password must contain a lowercase letter",
+		"digit":           "password must contain a digit",
+		"special":         "password must contain a special character",
+		"username":        "password must not equal the username",
+		"common":          "password is too common",
+	},
+}
+
+// MatchLanguage 从 Accept-Language 中选出第一个支持的语言，没有则返回默认语言
+// 📌 按出现顺序匹配主语言标签，不处理 q 权重
+func MatchLanguage(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if primary == DefaultLang {
+			return DefaultLang
+		}
+		if _, ok := messages[primary]; ok {
+			return primary
+		}
+	}
+	return DefaultLang
+}
+
+// Localize 返回指定语言的副本，文案与字段详情均已翻译
+func (e *AppError) Localize(lang string) *AppError {
+	cp := *e
+	if msg, ok := messages[lang][e.Key]; ok {
+		cp.Message = msg
+	}
+	if len(e.Details) > 0 {
+		cp.Details = make([]FieldError, len(e.Details))
+		for i, d := range e.Details {
+			cp.Details[i] = d.localize(lang)
+		}
+	}
+	return &cp
+}
+
+// localize 优先使用目标语言模板；没有模板时保留原文案，原文案为空再回退到默认语言模板
+func (f FieldError) localize(lang string) FieldError {
+	if tmpl, ok := fieldMessages[lang][f.Rule]; ok {
+		f.Message = f.format(tmpl)
+		return f
+	}
+	if f.Message != "" {
+		return f
+	}
+	if tmpl, ok := fieldMessages[DefaultLang][f.Rule]; ok {
+		f.Message = f.format(tmpl)
+	} else {
+		f.Message = fmt.Sprintf("%s 不满足 %s 规则", f.Field, f.Rule)
+	}
+	return f
+}
+
+func (f FieldError) format(tmpl string) string {
+	return strings.NewReplacer("{field}", f.Field, "{param}", f.Param).Replace(tmpl)
+}
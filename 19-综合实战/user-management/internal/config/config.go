@@ -10,33 +10,166 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server   ServerConfig            `mapstructure:"server"`
+	Database DatabaseConfig          `mapstructure:"database"`
+	JWT      JWTConfig               `mapstructure:"jwt"`
+	Log      LogConfig               `mapstructure:"log"`
+	RBAC     RBACConfig              `mapstructure:"rbac"`
+	User     UserConfig              `mapstructure:"user"`
+	Login    LoginProtectionConfig   `mapstructure:"login_protection"`
+	Password PasswordPolicyConfig    `mapstructure:"password_policy"`
+	Hash     PasswordHashConfig      `mapstructure:"password_hash"`
+	Mail     MailConfig              `mapstructure:"mail"`
+	Reset    PasswordResetConfig     `mapstructure:"password_reset"`
+	Verify   EmailVerificationConfig `mapstructure:"email_verification"`
+	MFA      MFAConfig               `mapstructure:"mfa"`
+	Admin    BootstrapAdminConfig    `mapstructure:"bootstrap_admin"`
+	Health   HealthConfig            `mapstructure:"health"`
 }
 
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port    int    `mapstructure:"port"`
+	Mode    string `mapstructure:"mode"`
+	BaseURL string `mapstructure:"base_url"` // 对外访问地址，用于生成邮件中的链接
+	// 优雅关闭: 收到退出信号后先标记未就绪并等待 shutdown_delay_seconds，
+	// 再最多等待 shutdown_timeout_seconds 让进行中的请求完成
+	ShutdownDelaySeconds   int `mapstructure:"shutdown_delay_seconds"`
+	ShutdownTimeoutSeconds int `mapstructure:"shutdown_timeout_seconds"`
 }
 
 type DatabaseConfig struct {
-	Driver string `mapstructure:"driver"`
-	DSN    string `mapstructure:"dsn"`
+	Driver string `mapstructure:"driver"` // sqlite / postgres / mysql
+	DSN    string `mapstructure:"dsn"`    // sqlite 为空时使用内存数据库
+	// 连接池，0 表示使用 database/sql 默认值
+	MaxOpenConns           int `mapstructure:"max_open_conns"`
+	MaxIdleConns           int `mapstructure:"max_idle_conns"`
+	ConnMaxLifetimeMinutes int `mapstructure:"conn_max_lifetime_minutes"`
+	// LogLevel GORM 日志级别: silent / error / warn / info
+	LogLevel string `mapstructure:"log_level"`
+	// QueryTimeoutSeconds 单条 SQL 的超时，0 表示不限制
+	QueryTimeoutSeconds int `mapstructure:"query_timeout_seconds"`
+	// AutoMigrate 启动时自动执行待执行的迁移；关闭时结构落后会拒绝启动
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
+// JWTConfig JWT 配置
+// 📌 keys 为空时使用 HS256 + secret；配置 keys 后使用非对称签名（RS256 / EdDSA，由密钥类型决定）
 type JWTConfig struct {
-	Secret      string `mapstructure:"secret"`
-	ExpireHours int    `mapstructure:"expire_hours"`
+	Secret              string         `mapstructure:"secret"`
+	Issuer              string         `mapstructure:"issuer"`
+	ActiveKID           string         `mapstructure:"active_kid"` // 当前用于签名的密钥 ID
+	Keys                []JWTKeyConfig `mapstructure:"keys"`
+	AccessExpireMinutes int            `mapstructure:"access_expire_minutes"` // access token 有效期（短）
+	RefreshExpireHours  int            `mapstructure:"refresh_expire_hours"`  // refresh token 有效期（长）
+	MFAPendingMinutes   int            `mapstructure:"mfa_pending_minutes"`   // 两步验证待完成 token 有效期
+}
+
+// JWTKeyConfig 签名密钥（PEM 文件）
+// 📌 配置私钥的密钥可签名也可校验；轮换下线的旧密钥可以只保留公钥，仅用于校验
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
 }
 
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
+	Format     string `mapstructure:"format"` // console / json
 	Filename   string `mapstructure:"filename"`
 	MaxSize    int    `mapstructure:"max_size"`
 	MaxBackups int    `mapstructure:"max_backups"`
 	MaxAge     int    `mapstructure:"max_age"`
+	Compress   bool   `mapstructure:"compress"`
+
+	Body   LogBodyConfig `mapstructure:"body"`
+	Redact RedactConfig  `mapstructure:"redact"`
+}
+
+// LogBodyConfig 请求/响应 body 日志，仅用于排查问题
+type LogBodyConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	MaxBytes int  `mapstructure:"max_bytes"` // 单个 body 最多记录的字节数
+}
+
+// RedactConfig 日志脱敏规则，在内置规则基础上追加
+type RedactConfig struct {
+	JSONPaths []string `mapstructure:"json_paths"`
+	Headers   []string `mapstructure:"headers"`
+	QueryKeys []string `mapstructure:"query_keys"`
+	// SensitivePaths 敏感接口路径前缀，无法解析脱敏的 body 不记录原文
+	SensitivePaths []string `mapstructure:"sensitive_paths"`
+}
+
+type RBACConfig struct {
+	PermissionSource string `mapstructure:"permission_source"` // claims / db
+}
+
+type UserConfig struct {
+	DeletedRetentionDays int `mapstructure:"deleted_retention_days"` // 软删除用户保留天数，超过后可彻底清除
+	PurgeIntervalHours   int `mapstructure:"purge_interval_hours"`   // 定时清除间隔，0 表示不启用定时任务
+}
+
+type LoginProtectionConfig struct {
+	MaxFailures          int `mapstructure:"max_failures"`           // 同一用户名连续失败多少次后锁定
+	IPMaxFailures        int `mapstructure:"ip_max_failures"`        // 同一 IP 失败多少次后限流
+	BaseLockoutSeconds   int `mapstructure:"base_lockout_seconds"`   // 首次锁定时长，之后逐次翻倍
+	MaxLockoutMinutes    int `mapstructure:"max_lockout_minutes"`    // 锁定时长上限
+	FailureWindowMinutes int `mapstructure:"failure_window_minutes"` // 距上次失败超过该时长后计数清零
+}
+
+type PasswordPolicyConfig struct {
+	MinLength           int    `mapstructure:"min_length"`
+	MaxBytes            int    `mapstructure:"max_bytes"` // 不超过 72（bcrypt 限制）
+	RequireUpper        bool   `mapstructure:"require_upper"`
+	RequireLower        bool   `mapstructure:"require_lower"`
+	RequireDigit        bool   `mapstructure:"require_digit"`
+	RequireSpecial      bool   `mapstructure:"require_special"`
+	DisallowUsername    bool   `mapstructure:"disallow_username"`     // 密码不能与用户名相同
+	CommonPasswordsFile string `mapstructure:"common_passwords_file"` // 常见密码黑名单，留空不启用
+}
+
+type PasswordHashConfig struct {
+	Algorithm         string `mapstructure:"algorithm"` // bcrypt / argon2id
+	BcryptCost        int    `mapstructure:"bcrypt_cost"`
+	Argon2MemoryKB    int    `mapstructure:"argon2_memory_kb"`
+	Argon2Iterations  int    `mapstructure:"argon2_iterations"`
+	Argon2Parallelism int    `mapstructure:"argon2_parallelism"`
+}
+
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"` // smtp / log
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	LogFile  string `mapstructure:"log_file"` // log 驱动: 邮件追加写入该文件，留空只写日志
+	LogBody  bool   `mapstructure:"log_body"` // log 驱动: 记录含令牌的邮件原文，仅限本地开发
+}
+
+type PasswordResetConfig struct {
+	TokenTTLMinutes int `mapstructure:"token_ttl_minutes"`
+	// 同一邮箱的请求频率限制，无论邮箱是否注册都生效
+	RequestCooldownSeconds int `mapstructure:"request_cooldown_seconds"` // 两次请求的最小间隔
+	RequestMaxPerDay       int `mapstructure:"request_max_per_day"`      // 24 小时内最多请求次数
+}
+
+type EmailVerificationConfig struct {
+	RequireBeforeLogin    bool `mapstructure:"require_before_login"` // 未验证邮箱是否禁止登录
+	TokenTTLHours         int  `mapstructure:"token_ttl_hours"`
+	ResendCooldownSeconds int  `mapstructure:"resend_cooldown_seconds"` // 同一邮箱两次重发的最小间隔
+	ResendMaxPerDay       int  `mapstructure:"resend_max_per_day"`      // 同一邮箱 24 小时内最多重发次数
+}
+
+type MFAConfig struct {
+	Issuer            string `mapstructure:"issuer"`              // 验证器 App 中显示的发行方
+	Skew              int    `mapstructure:"skew"`                // 允许前后偏差的时间步数（每步 30 秒）
+	RecoveryCodeCount int    `mapstructure:"recovery_code_count"` // 恢复码数量
+}
+
+type HealthConfig struct {
+	CheckTimeoutSeconds int `mapstructure:"check_timeout_seconds"` // 单项就绪检查超时，0 表示不限制
+	MinFreeDiskMB       int `mapstructure:"min_free_disk_mb"`      // SQLite 数据目录最小剩余空间，0 表示不检查
 }
 
 // Load 加载配置
@@ -55,3 +188,12 @@ func Load(configPath string) (*Config, error) {
 
 	return &config, nil
 }
+
+// BootstrapAdminConfig 启动时创建初始管理员
+// 📌 username 为空时不启用；只在 users 表为空时创建，已有任何用户（包括软删除的）时跳过
+// 📌 初始密码首次登录后必须修改
+type BootstrapAdminConfig struct {
+	Username string `mapstructure:"username"`
+	Email    string `mapstructure:"email"`
+	Password string `mapstructure:"password"`
+}
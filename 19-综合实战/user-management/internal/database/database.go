@@ -0,0 +1,164 @@
+// internal/database/database.go - 数据库连接
+//
+// 📌 根据 database.driver 从驱动注册表中选择 Dialector:
+//   - sqlite: 本地开发；dsn 为空时使用内存数据库，CI 无需外部依赖
+//   - postgres: dsn 形如 "host=localhost user=app password=secret dbname=app port=5432 sslmode=disable"
+//   - mysql: dsn 形如 "app:secret@tcp(127.0.0.1:3306)/app?charset=utf8mb4&parseTime=True&loc=Local"
+//
+// 📌 连接池与 GORM 日志级别均来自配置，SQL 日志经 zap 输出（见 logger.go）
+// 📌 MySQL 不支持部分索引，用户名/邮箱唯一约束建在生成列上，同样只约束未删除的用户（见 migrate/sql/mysql）
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"user-management/internal/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// MemoryDSN SQLite 内存数据库
+const MemoryDSN = ":memory:"
+
+// Opener 根据 DSN 创建 GORM Dialector
+type Opener func(dsn string) gorm.Dialector
+
+var drivers = map[string]Opener{
+	DriverSQLite:   sqlite.Open,
+	DriverPostgres: postgres.Open,
+	DriverMySQL:    mysql.Open,
+}
+
+// Register 注册数据库驱动，同名驱动会被覆盖
+func Register(name string, opener Opener) {
+	drivers[name] = opener
+}
+
+// Open 按配置打开数据库连接并设置连接池
+func Open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	opener, ok := drivers[driver]
+	if !ok {
+		return nil, fmt.Errorf("不支持的数据库驱动 %q（可选: %s）", driver, strings.Join(Drivers(), ", "))
+	}
+
+	dsn := cfg.DSN
+	if driver == DriverSQLite {
+		if dsn == "" {
+			dsn = MemoryDSN
+		}
+		// 确保数据库文件所在目录存在
+		if path := sqliteFilePath(dsn); path != "" {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	level, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(opener(dsn), &gorm.Config{Logger: newLogger(level)})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.QueryTimeoutSeconds > 0 {
+		if err := db.Use(&queryTimeout{timeout: time.Duration(cfg.QueryTimeoutSeconds) * time.Second}); err != nil {
+			return nil, err
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if driver == DriverSQLite && sqliteFilePath(dsn) == "" {
+		// 📌 每个内存数据库连接都是独立的库，只保留一个连接且永不关闭
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		return db, nil
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutes) * time.Minute)
+	}
+
+	return db, nil
+}
+
+// Drivers 返回已注册的驱动名
+func Drivers() []string {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SQLiteDataDir 返回 SQLite 数据库文件所在目录；非 SQLite 或内存数据库返回空串
+func SQLiteDataDir(cfg *config.DatabaseConfig) string {
+	if cfg.Driver != "" && cfg.Driver != DriverSQLite {
+		return ""
+	}
+	path := sqliteFilePath(cfg.DSN)
+	if path == "" {
+		return ""
+	}
+	return filepath.Dir(path)
+}
+
+// sqliteFilePath 返回 SQLite DSN 对应的文件路径，内存数据库返回空串
+func sqliteFilePath(dsn string) string {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		if strings.Contains(path[i:], "mode=memory") {
+			return ""
+		}
+		path = path[:i]
+	}
+	if path == "" || path == MemoryDSN {
+		return ""
+	}
+	return path
+}
+
+func parseLogLevel(level string) (logger.LogLevel, error) {
+	switch level {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "", "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	default:
+		return 0, fmt.Errorf("无效的数据库日志级别 %q（可选: silent, error, warn, info）", level)
+	}
+}
@@ -0,0 +1,72 @@
+// internal/database/logger.go - GORM 日志适配 zap
+//
+// 📌 SQL 日志从 ctx 中取请求级 logger（logging.FromContext），与 HTTP 日志共享 request_id
+// 📌 查不到记录是正常业务分支，不记为错误
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"user-management/internal/logging"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowThreshold 超过该耗时的 SQL 记为慢查询
+const slowThreshold = 200 * time.Millisecond
+
+type zapLogger struct {
+	level logger.LogLevel
+}
+
+func newLogger(level logger.LogLevel) logger.Interface {
+	return &zapLogger{level: level}
+}
+
+func (l *zapLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &zapLogger{level: level}
+}
+
+func (l *zapLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		logging.FromContext(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *zapLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		logging.FromContext(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *zapLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		logging.FromContext(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *zapLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		logging.FromContext(ctx).Error("SQL 执行失败",
+			zap.Error(err), zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	case elapsed > slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		logging.FromContext(ctx).Warn("慢查询",
+			zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		logging.FromContext(ctx).Info("SQL",
+			zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	}
+}
@@ -0,0 +1,76 @@
+// internal/database/timeout.go - 单条 SQL 超时
+//
+// 📌 query_timeout_seconds 作用于每一条 SQL，而不是整个请求: 请求内执行多少条查询都不会互相挤占时间
+// 📌 在 GORM 回调中为语句的 context 加截止时间，调用方的 context（请求取消、更早的截止时间）仍然生效
+// 📌 超时返回 context.DeadlineExceeded，由 ErrorMiddleware 渲染为 504
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const timeoutStateKey = "database:query_timeout"
+
+// queryTimeout 单条 SQL 超时插件
+type queryTimeout struct {
+	timeout time.Duration
+}
+
+// timeoutState 语句执行期间保存的原 context 和取消函数
+type timeoutState struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+func (p *queryTimeout) Name() string {
+	return "database:query_timeout"
+}
+
+func (p *queryTimeout) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("timeout:before_create", p.before),
+		cb.Create().After("gorm:create").Register("timeout:after_create", p.after(true)),
+		cb.Query().Before("gorm:query").Register("timeout:before_query", p.before),
+		cb.Query().After("gorm:query").Register("timeout:after_query", p.after(true)),
+		cb.Update().Before("gorm:update").Register("timeout:before_update", p.before),
+		cb.Update().After("gorm:update").Register("timeout:after_update", p.after(true)),
+		cb.Delete().Before("gorm:delete").Register("timeout:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Register("timeout:after_delete", p.after(true)),
+		cb.Raw().Before("gorm:raw").Register("timeout:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("timeout:after_raw", p.after(true)),
+		// 📌 Row/Rows/Scan 在回调返回后才读取结果，不能提前取消，由截止时间到期释放
+		cb.Row().Before("gorm:row").Register("timeout:before_row", p.before),
+		cb.Row().After("gorm:row").Register("timeout:after_row", p.after(false)),
+	)
+}
+
+func (p *queryTimeout) before(db *gorm.DB) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, p.timeout)
+	db.InstanceSet(timeoutStateKey, &timeoutState{parent: db.Statement.Context, cancel: cancel})
+	db.Statement.Context = ctx
+}
+
+// after 恢复原 context
+// 📌 同一个 *gorm.DB 链上先 Count 再 Find 时共用 Statement，不恢复的话后一条语句会拿到已取消的 context
+func (p *queryTimeout) after(release bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(timeoutStateKey)
+		if !ok {
+			return
+		}
+		state := value.(*timeoutState)
+		db.Statement.Context = state.parent
+		if release {
+			state.cancel()
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-management/internal/config"
+
+	"gorm.io/gorm"
+)
+
+type timeoutItem struct {
+	ID   uint
+	Name string
+}
+
+func openWithTimeout(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Open(&config.DatabaseConfig{Driver: DriverSQLite, LogLevel: "silent", QueryTimeoutSeconds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&timeoutItem{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&[]timeoutItem{{Name: "a"}, {Name: "b"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestQueryTimeoutPerStatement(t *testing.T) {
+	db := openWithTimeout(t)
+	ctx := context.Background()
+
+	// 同一条链上的多条语句各自计时，前一条结束后取消的 context 不影响后一条
+	q := db.WithContext(ctx).Model(&timeoutItem{}).Where("name <> ?", "")
+	var count int64
+	if err := q.Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("Count = (%d, %v), want (2, nil)", count, err)
+	}
+	var items []timeoutItem
+	if err := q.Find(&items).Error; err != nil || len(items) != 2 {
+		t.Fatalf("Find = (%d, %v), want (2, nil)", len(items), err)
+	}
+
+	// Scan 在回调返回后读取结果
+	var names []string
+	if err := db.WithContext(ctx).Raw("SELECT name FROM timeout_items ORDER BY id").Scan(&names).Error; err != nil || len(names) != 2 {
+		t.Fatalf("Scan = (%v, %v)", names, err)
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&timeoutItem{Name: "c"}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&timeoutItem{}).Where("name = ?", "a").Update("name", "A").Error
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+}
+
+func TestQueryTimeoutExceeded(t *testing.T) {
+	db := openWithTimeout(t)
+
+	start := time.Now()
+	var n int64
+	err := db.WithContext(context.Background()).
+		Raw("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c").
+		Scan(&n).Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("query ran for %v", elapsed)
+	}
+
+	// 超时的语句不影响后续查询
+	var count int64
+	if err := db.Model(&timeoutItem{}).Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("Count after timeout = (%d, %v), want (2, nil)", count, err)
+	}
+}
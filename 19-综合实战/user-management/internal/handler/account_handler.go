@@ -0,0 +1,92 @@
+// internal/handler/account_handler.go - 账号自助处理器
+package handler
+
+import (
+	"net/http"
+	"user-management/internal/apperr"
+	"user-management/internal/model"
+	"user-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+}
+
+func NewAccountHandler(service service.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// RegisterRoutes 注册路由（均为公开路由）
+func (h *AccountHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+	r.GET("/verify-email", h.VerifyEmail)
+	r.POST("/verify-email/resend", h.ResendVerification)
+}
+
+// ForgotPassword 忘记密码，发送重置邮件
+// 📌 无论邮箱是否注册，响应都相同
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "如果该邮箱已注册，重置邮件将很快送达"})
+}
+
+// ResetPassword 使用邮件中的令牌重置密码
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), &req, actorFrom(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "密码已重置，请重新登录"})
+}
+
+// VerifyEmail 邮件中的验证链接
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(apperr.InvalidParams.WithDetails(apperr.FieldError{Field: "token", Rule: "required"}))
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), token, actorFrom(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "邮箱验证成功"})
+}
+
+// ResendVerification 重新发送验证邮件
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	var req model.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	if err := h.service.ResendVerification(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "如果该邮箱已注册且未验证，验证邮件将很快送达"})
+}
@@ -0,0 +1,20 @@
+// internal/handler/actor.go - 从请求中提取操作者信息
+package handler
+
+import (
+	"user-management/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorFrom 提取当前请求的操作者（审计日志使用）
+// 📌 未认证的请求 UserID 为 0、Username 为空
+func actorFrom(c *gin.Context) *model.Actor {
+	return &model.Actor{
+		UserID:    c.GetUint("userID"),
+		Username:  c.GetString("username"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("requestID"),
+	}
+}
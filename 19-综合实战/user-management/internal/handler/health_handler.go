@@ -0,0 +1,41 @@
+// internal/handler/health_handler.go - 存活与就绪探针
+//
+// 📌 探针由编排系统和负载均衡调用，不经过认证，直接输出检查结果而不包装统一响应结构
+package handler
+
+import (
+	"net/http"
+	"user-management/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	health *health.Health
+}
+
+func NewHealthHandler(h *health.Health) *HealthHandler {
+	return &HealthHandler{health: h}
+}
+
+// RegisterRoutes 注册路由（挂在根路由，不在 /api 下）
+func (h *HealthHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/livez", h.Live)
+	r.GET("/readyz", h.Ready)
+}
+
+// Live 存活检查，不检查任何依赖
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Ready 就绪检查，未就绪返回 503 及各检查项的结果
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.health.Readiness(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-management/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	probes := health.New(0)
+	var dbErr error
+	probes.Register("database", health.CheckerFunc(func(ctx context.Context) error { return dbErr }))
+
+	r := gin.New()
+	NewHealthHandler(probes).RegisterRoutes(r)
+	get := func(path string) (int, *health.Report, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var report health.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, &report, rec
+	}
+
+	if status, report, rec := get("/readyz"); status != http.StatusOK || report.Status != health.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("ready: %d %+v", status, report)
+	}
+
+	dbErr = errors.New("connection refused")
+	status, report, rec := get("/readyz")
+	if status != http.StatusServiceUnavailable || report.Checks["database"].Error != health.ReasonFailed {
+		t.Fatalf("database down: %d %+v", status, report)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "connection refused") {
+		t.Errorf("response leaks check error: %s", body)
+	}
+
+	// 退出期间就绪检查立即失败，存活检查不受影响
+	dbErr = nil
+	probes.SetShuttingDown()
+	if status, report, _ := get("/readyz"); status != http.StatusServiceUnavailable || report.Status != health.StatusShuttingDown {
+		t.Fatalf("shutting down: %d %+v", status, report)
+	}
+	if status, report, _ := get("/livez"); status != http.StatusOK || report.Status != health.StatusOK {
+		t.Fatalf("live while shutting down: %d %+v", status, report)
+	}
+}
@@ -0,0 +1,29 @@
+// internal/handler/jwks_handler.go - JWKS 公钥发布
+package handler
+
+import (
+	"net/http"
+	"user-management/internal/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keySet *jwtkeys.KeySet
+}
+
+func NewJWKSHandler(keySet *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keySet: keySet}
+}
+
+// RegisterRoutes 注册路由（挂在根路由，不在 /api 下）
+func (h *JWKSHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+// JWKS 返回公钥集合，供其他服务校验本服务签发的 token
+// 📌 直接输出 RFC 7517 格式，不包装统一响应结构
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
@@ -0,0 +1,65 @@
+// internal/handler/log_handler.go - 运行时日志级别
+//
+// 📌 修改的是进程内的 zap.AtomicLevel，重启后恢复为配置文件中的级别
+// 📌 多实例部署时需要对每个实例分别调用
+package handler
+
+import (
+	"net/http"
+	"user-management/internal/logging"
+	"user-management/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type LogHandler struct {
+	level zap.AtomicLevel
+}
+
+func NewLogHandler(level zap.AtomicLevel) *LogHandler {
+	return &LogHandler{level: level}
+}
+
+// RegisterRoutes 注册路由
+func (h *LogHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, requirePermission func(permission string) gin.HandlerFunc) {
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware)
+	{
+		admin.GET("/log-level", requirePermission(model.PermSystemLog), h.GetLevel)
+		admin.PUT("/log-level", requirePermission(model.PermSystemLog), h.SetLevel)
+	}
+}
+
+// GetLevel 查询当前日志级别
+func (h *LogHandler) GetLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "success", Data: model.LogLevelResponse{Level: h.level.String()}})
+}
+
+// SetLevel 修改日志级别，立即生效
+func (h *LogHandler) SetLevel(c *gin.Context) {
+	var req model.LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	previous := h.level.String()
+	if err := h.level.UnmarshalText([]byte(req.Level)); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	// 📌 至少以 Warn 记录，并且不低于新级别，保证这条日志一定可见
+	lvl := zapcore.WarnLevel
+	if h.level.Level() > lvl {
+		lvl = h.level.Level()
+	}
+	logging.FromContext(c.Request.Context()).Log(lvl, "日志级别已修改",
+		zap.String("from", previous),
+		zap.String("to", req.Level),
+		zap.String("operator", c.GetString("username")),
+	)
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "修改成功", Data: model.LogLevelResponse{Level: h.level.String()}})
+}
@@ -0,0 +1,78 @@
+// internal/handler/response.go - 统一响应与参数错误
+//
+// 📌 业务错误统一 c.Error(err) 后返回，由 middleware.ErrorMiddleware 渲染
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"user-management/internal/apperr"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// invalidParams 将参数绑定错误转换为 apperr.InvalidParams，校验失败的字段放入详情
+func invalidParams(err error) error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]apperr.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			details[i] = apperr.FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()}
+		}
+		return apperr.InvalidParams.WithDetails(details...).Wrap(err)
+	case errors.As(err, &typeErr):
+		return apperr.InvalidParams.WithDetails(apperr.FieldError{
+			Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String(),
+		}).Wrap(err)
+	default:
+		return apperr.InvalidParams.Wrap(err)
+	}
+}
+
+// 📌 校验错误中的字段名使用 json/form tag，与客户端提交的字段名一致
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// Response 统一响应
+type Response struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// PageData 分页数据
+type PageData struct {
+	List     interface{} `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// CursorPageData 游标分页数据
+type CursorPageData struct {
+	List       interface{} `json:"list"`
+	NextCursor string      `json:"next_cursor"`
+	HasMore    bool        `json:"has_more"`
+	PageSize   int         `json:"page_size"`
+}
@@ -0,0 +1,155 @@
+// internal/handler/role_handler.go - 角色权限处理器
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"user-management/internal/apperr"
+	"user-management/internal/model"
+	"user-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	service service.RBACService
+}
+
+func NewRoleHandler(service service.RBACService) *RoleHandler {
+	return &RoleHandler{service: service}
+}
+
+// RegisterRoutes 注册路由
+func (h *RoleHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, requirePermission func(permission string) gin.HandlerFunc) {
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware)
+	{
+		admin.GET("/roles", requirePermission(model.PermRoleRead), h.ListRoles)
+		admin.POST("/roles", requirePermission(model.PermRoleManage), h.CreateRole)
+		admin.PUT("/roles/:id", requirePermission(model.PermRoleManage), h.UpdateRole)
+		admin.DELETE("/roles/:id", requirePermission(model.PermRoleManage), h.DeleteRole)
+
+		admin.GET("/permissions", requirePermission(model.PermRoleRead), h.ListPermissions)
+		admin.POST("/permissions", requirePermission(model.PermRoleManage), h.CreatePermission)
+
+		admin.PUT("/users/:id/roles", requirePermission(model.PermRoleManage), h.AssignUserRoles)
+	}
+}
+
+// ListRoles 角色列表
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "success", Data: roles})
+}
+
+// CreateRole 创建角色
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req model.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), &req, actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{Code: 0, Message: "创建成功", Data: role})
+}
+
+// UpdateRole 更新角色描述及权限（覆盖式）
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), uint(id), &req, actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "更新成功", Data: role})
+}
+
+// DeleteRole 删除角色
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteRole(c.Request.Context(), uint(id), actorFrom(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "删除成功"})
+}
+
+// ListPermissions 权限列表
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	perms, err := h.service.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "success", Data: perms})
+}
+
+// CreatePermission 创建权限
+func (h *RoleHandler) CreatePermission(c *gin.Context) {
+	var req model.CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	perm, err := h.service.CreatePermission(c.Request.Context(), &req, actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{Code: 0, Message: "创建成功", Data: perm})
+}
+
+// AssignUserRoles 设置用户角色
+func (h *RoleHandler) AssignUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	var req model.AssignRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	user, err := h.service.AssignUserRoles(c.Request.Context(), uint(id), &req, actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "分配成功", Data: user})
+}
@@ -2,29 +2,34 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
+	"user-management/internal/apperr"
 	"user-management/internal/model"
-	"user-management/internal/repository"
 	"user-management/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	service service.UserService
+	service      service.UserService
+	tokenService service.TokenService
 }
 
-func NewUserHandler(service service.UserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(service service.UserService, tokenService service.TokenService) *UserHandler {
+	return &UserHandler{
+		service:      service,
+		tokenService: tokenService,
+	}
 }
 
 // RegisterRoutes 注册路由
-func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware, adminMiddleware gin.HandlerFunc) {
+func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, requirePermission func(permission string) gin.HandlerFunc) {
 	// 公开路由
 	r.POST("/register", h.Register)
 	r.POST("/login", h.Login)
+	r.POST("/login/mfa", h.LoginMFA)
+	r.POST("/token/refresh", h.RefreshToken)
 
 	// 需要认证的路由
 	auth := r.Group("")
@@ -33,14 +38,21 @@ func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware, adminMi
 		auth.GET("/profile", h.GetProfile)
 		auth.PUT("/profile", h.UpdateProfile)
 		auth.PUT("/password", h.ChangePassword)
+		auth.POST("/logout", h.Logout)
 	}
 
 	// 管理员路由
 	admin := r.Group("/admin")
-	admin.Use(authMiddleware, adminMiddleware)
+	admin.Use(authMiddleware)
 	{
-		admin.GET("/users", h.GetUsers)
-		admin.DELETE("/users/:id", h.DeleteUser)
+		admin.GET("/users", requirePermission(model.PermUserRead), h.GetUsers)
+		admin.DELETE("/users/:id", requirePermission(model.PermUserDelete), h.DeleteUser)
+		admin.POST("/users/:id/restore", requirePermission(model.PermUserRestore), h.RestoreUser)
+		admin.POST("/users/purge", requirePermission(model.PermUserPurge), h.PurgeUsers)
+		admin.POST("/users/:id/disable", requirePermission(model.PermUserStatus), h.DisableUser)
+		admin.POST("/users/:id/enable", requirePermission(model.PermUserStatus), h.EnableUser)
+		admin.POST("/users/:id/lock", requirePermission(model.PermUserStatus), h.LockUser)
+		admin.POST("/users/:id/unlock", requirePermission(model.PermUserStatus), h.UnlockUser)
 	}
 }
 
@@ -55,13 +67,13 @@ func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware, adminMi
 func (h *UserHandler) Register(c *gin.Context) {
 	var req model.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "参数错误: " + err.Error()})
+		c.Error(invalidParams(err))
 		return
 	}
 
-	user, err := h.service.Register(&req)
+	user, err := h.service.Register(c.Request.Context(), &req, actorFrom(c))
 	if err != nil {
-		h.handleError(c, err)
+		c.Error(err)
 		return
 	}
 
@@ -72,26 +84,78 @@ func (h *UserHandler) Register(c *gin.Context) {
 func (h *UserHandler) Login(c *gin.Context) {
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "参数错误"})
+		c.Error(invalidParams(err))
 		return
 	}
 
-	resp, err := h.service.Login(&req)
+	resp, err := h.service.Login(c.Request.Context(), &req, actorFrom(c))
 	if err != nil {
-		h.handleError(c, err)
+		c.Error(err)
+		return
+	}
+
+	if resp.MFARequired {
+		c.JSON(http.StatusOK, Response{Code: 0, Message: "请输入两步验证码", Data: resp})
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{Code: 0, Message: "登录成功", Data: resp})
 }
 
+// LoginMFA 两步登录第二步
+func (h *UserHandler) LoginMFA(c *gin.Context) {
+	var req model.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	resp, err := h.service.LoginMFA(c.Request.Context(), &req, actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "登录成功", Data: resp})
+}
+
+// RefreshToken 刷新 token
+// 📌 旧 refresh token 使用后立即失效，客户端需保存新返回的 refresh token
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
+
+	resp, err := h.tokenService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "刷新成功", Data: resp})
+}
+
+// Logout 退出登录，吊销当前会话
+func (h *UserHandler) Logout(c *gin.Context) {
+	sessionID := c.GetUint("sessionID")
+
+	if err := h.tokenService.RevokeSession(c.Request.Context(), sessionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "已退出登录"})
+}
+
 // GetProfile 获取个人信息
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	user, err := h.service.GetProfile(userID)
+	user, err := h.service.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		h.handleError(c, err)
+		c.Error(err)
 		return
 	}
 
@@ -104,13 +168,13 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	var req model.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "参数错误"})
+		c.Error(invalidParams(err))
 		return
 	}
 
-	user, err := h.service.UpdateProfile(userID, &req)
+	user, err := h.service.UpdateProfile(c.Request.Context(), userID, &req, actorFrom(c))
 	if err != nil {
-		h.handleError(c, err)
+		c.Error(err)
 		return
 	}
 
@@ -123,12 +187,12 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 
 	var req model.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "参数错误"})
+		c.Error(invalidParams(err))
 		return
 	}
 
-	if err := h.service.ChangePassword(userID, &req); err != nil {
-		h.handleError(c, err)
+	if err := h.service.ChangePassword(c.Request.Context(), userID, &req, actorFrom(c)); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -136,20 +200,30 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 }
 
 // GetUsers 获取用户列表（管理员）
+// 📌 支持 keyword、role、created_from、created_to 过滤，sort_by + sort_order 排序
+// 📌 默认 offset 分页；pagination=cursor 时返回 next_cursor，不统计总数
 func (h *UserHandler) GetUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	var query model.UserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(invalidParams(err))
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	if query.Page < 1 {
+		query.Page = 1
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 10
 	}
 
-	users, total, err := h.service.GetUsers(page, pageSize)
+	if query.IsCursorMode() {
+		h.getUsersByCursor(c, &query)
+		return
+	}
+
+	users, total, err := h.service.GetUsers(c.Request.Context(), &query)
 	if err != nil {
-		h.handleError(c, err)
+		c.Error(err)
 		return
 	}
 
@@ -159,56 +233,139 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		Data: PageData{
 			List:     users,
 			Total:    total,
-			Page:     page,
-			PageSize: pageSize,
+			Page:     query.Page,
+			PageSize: query.PageSize,
+		},
+	})
+}
+
+func (h *UserHandler) getUsersByCursor(c *gin.Context, query *model.UserQuery) {
+	users, nextCursor, err := h.service.GetUsersByCursor(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    0,
+		Message: "success",
+		Data: CursorPageData{
+			List:       users,
+			NextCursor: nextCursor,
+			HasMore:    nextCursor != "",
+			PageSize:   query.PageSize,
 		},
 	})
 }
 
-// DeleteUser 删除用户（管理员）
+// DeleteUser 删除用户（管理员，软删除）
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "无效的ID"})
+		c.Error(apperr.InvalidID)
 		return
 	}
 
-	if err := h.service.DeleteUser(uint(id)); err != nil {
-		h.handleError(c, err)
+	if err := h.service.DeleteUser(c.Request.Context(), uint(id), actorFrom(c)); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{Code: 0, Message: "删除成功"})
 }
 
-func (h *UserHandler) handleError(c *gin.Context, err error) {
-	switch {
-	case errors.Is(err, repository.ErrUserNotFound):
-		c.JSON(http.StatusNotFound, Response{Code: 404, Message: "用户不存在"})
-	case errors.Is(err, service.ErrInvalidCredentials):
-		c.JSON(http.StatusUnauthorized, Response{Code: 401, Message: "用户名或密码错误"})
-	case errors.Is(err, service.ErrUsernameExists):
-		c.JSON(http.StatusConflict, Response{Code: 409, Message: "用户名已存在"})
-	case errors.Is(err, service.ErrEmailExists):
-		c.JSON(http.StatusConflict, Response{Code: 409, Message: "邮箱已存在"})
-	case errors.Is(err, service.ErrWrongPassword):
-		c.JSON(http.StatusBadRequest, Response{Code: 400, Message: "原密码错误"})
-	default:
-		c.JSON(http.StatusInternalServerError, Response{Code: 500, Message: "服务器错误"})
-	}
-}
-
-// Response 统一响应
-type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
-// PageData 分页数据
-type PageData struct {
-	List     interface{} `json:"list"`
-	Total    int64       `json:"total"`
-	Page     int         `json:"page"`
-	PageSize int         `json:"page_size"`
+// RestoreUser 恢复已删除用户（管理员）
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	user, err := h.service.RestoreUser(c.Request.Context(), uint(id), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "恢复成功", Data: user})
+}
+
+// PurgeUsers 彻底清除超过保留期的已删除用户（管理员）
+func (h *UserHandler) PurgeUsers(c *gin.Context) {
+	purged, err := h.service.PurgeDeletedUsers(c.Request.Context(), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "清除成功", Data: gin.H{"purged": purged}})
+}
+
+// DisableUser 禁用用户（管理员）
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	user, err := h.service.DisableUser(c.Request.Context(), uint(id), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "已禁用", Data: user})
+}
+
+// EnableUser 启用用户（管理员）
+func (h *UserHandler) EnableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	user, err := h.service.EnableUser(c.Request.Context(), uint(id), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "已启用", Data: user})
+}
+
+// LockUser 锁定用户（管理员）
+func (h *UserHandler) LockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	user, err := h.service.LockUser(c.Request.Context(), uint(id), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "已锁定", Data: user})
+}
+
+// UnlockUser 解除登录锁定（管理员）
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperr.InvalidID)
+		return
+	}
+
+	user, err := h.service.UnlockUser(c.Request.Context(), uint(id), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Code: 0, Message: "已解锁", Data: user})
 }
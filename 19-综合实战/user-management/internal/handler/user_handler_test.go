@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/jwtkeys"
+	"user-management/internal/middleware"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/service"
+	"user-management/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const testPassword = "Tom@2024!pw"
+
+// nopMailer 丢弃全部邮件
+type nopMailer struct{}
+
+func (nopMailer) Send(to, subject, body string) error { return nil }
+
+// testResponse 统一响应与错误响应的并集
+type testResponse struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	Data    json.RawMessage     `json:"data"`
+	Details []apperr.FieldError `json:"details"`
+}
+
+type testServer struct {
+	router *gin.Engine
+	admins service.AdminService
+}
+
+// newTestServer 按 main.go 的方式组装路由和中间件，数据库为内存 SQLite
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db := testutil.NewDB(t)
+
+	jwtConfig := &config.JWTConfig{Secret: strings.Repeat("s", 32), AccessExpireMinutes: 15, RefreshExpireHours: 1, MFAPendingMinutes: 5}
+	keySet, err := jwtkeys.Load(jwtConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := password.NewPolicy(&config.PasswordPolicyConfig{MinLength: 8, MaxBytes: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := testutil.Hasher(t)
+	loginConfig := &config.LoginProtectionConfig{MaxFailures: 5, IPMaxFailures: 100, BaseLockoutSeconds: 60, MaxLockoutMinutes: 15, FailureWindowMinutes: 15}
+
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	rbacRepo := repository.NewRBACRepository(db)
+	attemptRepo := repository.NewLoginAttemptRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	tokenService := service.NewTokenService(sessionRepo, userRepo, keySet, jwtConfig)
+	loginGuard := service.NewLoginGuard(attemptRepo, loginConfig, service.NopAuthEvents{})
+	accountService := service.NewAccountService(userRepo, repository.NewUserTokenRepository(db), txManager, attemptRepo, loginGuard,
+		policy, hasher, nopMailer{}, "https://example.com", &config.PasswordResetConfig{TokenTTLMinutes: 30}, &config.EmailVerificationConfig{TokenTTLHours: 24})
+	mfaService := service.NewMFAService(repository.NewMFARepository(db), userRepo, txManager, hasher, loginGuard,
+		&config.MFAConfig{Issuer: "test", Skew: 1, RecoveryCodeCount: 3}, time.Now)
+	userService := service.NewUserService(userRepo, rbacRepo, repository.NewAuditRepository(db), txManager, tokenService, loginGuard,
+		policy, hasher, accountService, mfaService, &config.UserConfig{}, &config.EmailVerificationConfig{}, service.NopAuthEvents{})
+	rbacService := service.NewRBACService(rbacRepo, userRepo, txManager)
+	if err := rbacService.SeedDefaults(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware(zap.NewNop()))
+	r.Use(middleware.ErrorMiddleware())
+	api := r.Group("/api")
+	requirePermission := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(nil, permission)
+	}
+	NewUserHandler(userService, tokenService).RegisterRoutes(api, middleware.AuthMiddleware(tokenService), requirePermission)
+	r.NoRoute(middleware.NotFound)
+
+	return &testServer{
+		router: r,
+		admins: service.NewAdminService(userRepo, rbacRepo, txManager, policy, hasher),
+	}
+}
+
+// do 发送请求，body 为 nil 时不带请求体，为 string 时原样发送
+func (s *testServer) do(t *testing.T, method, path, token string, body interface{}) (int, *testResponse) {
+	t.Helper()
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var resp testResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q", method, path, rec.Body.String())
+	}
+	return rec.Code, &resp
+}
+
+// login 登录并返回 token 对
+func (s *testServer) login(t *testing.T, username, pw string) *model.LoginResponse {
+	t.Helper()
+	status, resp := s.do(t, http.MethodPost, "/api/login", "", model.LoginRequest{Username: username, Password: pw})
+	if status != http.StatusOK {
+		t.Fatalf("login %s: status = %d, body = %+v", username, status, resp)
+	}
+	var tokens model.LoginResponse
+	if err := json.Unmarshal(resp.Data, &tokens); err != nil {
+		t.Fatal(err)
+	}
+	return &tokens
+}
+
+func (s *testServer) register(t *testing.T, username string) {
+	t.Helper()
+	status, resp := s.do(t, http.MethodPost, "/api/register", "", model.RegisterRequest{Username: username, Email: username + "@example.com", Password: testPassword})
+	if status != http.StatusCreated {
+		t.Fatalf("register %s: status = %d, body = %+v", username, status, resp)
+	}
+}
+
+func TestUserSessionFlow(t *testing.T) {
+	s := newTestServer(t)
+	s.register(t, "tom")
+
+	status, resp := s.do(t, http.MethodPost, "/api/register", "", model.RegisterRequest{Username: "tom", Email: "other@example.com", Password: testPassword})
+	if status != http.StatusConflict || resp.Code != apperr.UsernameExists.Code {
+		t.Fatalf("duplicate register: %d %+v", status, resp)
+	}
+	status, resp = s.do(t, http.MethodPost, "/api/login", "", model.LoginRequest{Username: "tom", Password: "wrong-password"})
+	if status != http.StatusUnauthorized || resp.Code != apperr.InvalidCredentials.Code {
+		t.Fatalf("wrong password: %d %+v", status, resp)
+	}
+
+	tokens := s.login(t, "tom", testPassword)
+	status, resp = s.do(t, http.MethodGet, "/api/profile", tokens.Token, nil)
+	var profile model.UserResponse
+	if err := json.Unmarshal(resp.Data, &profile); status != http.StatusOK || err != nil || profile.Username != "tom" {
+		t.Fatalf("profile: %d %+v", status, resp)
+	}
+
+	// 刷新后旧 refresh token 失效，重放会吊销整个会话
+	status, resp = s.do(t, http.MethodPost, "/api/token/refresh", "", model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	var refreshed model.LoginResponse
+	if err := json.Unmarshal(resp.Data, &refreshed); status != http.StatusOK || err != nil || refreshed.RefreshToken == "" {
+		t.Fatalf("refresh: %d %+v", status, resp)
+	}
+	status, resp = s.do(t, http.MethodPost, "/api/token/refresh", "", model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	if status != http.StatusUnauthorized || resp.Code != apperr.InvalidRefreshToken.Code {
+		t.Fatalf("replayed refresh token: %d %+v", status, resp)
+	}
+	if status, resp = s.do(t, http.MethodGet, "/api/profile", refreshed.Token, nil); status != http.StatusUnauthorized {
+		t.Fatalf("profile after reuse: %d %+v", status, resp)
+	}
+
+	// 退出登录后 access token 立即失效
+	tokens = s.login(t, "tom", testPassword)
+	if status, resp = s.do(t, http.MethodPost, "/api/logout", tokens.Token, nil); status != http.StatusOK {
+		t.Fatalf("logout: %d %+v", status, resp)
+	}
+	status, resp = s.do(t, http.MethodGet, "/api/profile", tokens.Token, nil)
+	if status != http.StatusUnauthorized || resp.Code != apperr.InvalidToken.Code {
+		t.Fatalf("profile after logout: %d %+v", status, resp)
+	}
+}
+
+func TestUserHandlerErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.register(t, "tom")
+	userToken := s.login(t, "tom", testPassword).Token
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		token       string
+		body        interface{}
+		wantStatus  int
+		wantCode    int
+		wantDetails []string // 期望的 field:rule
+	}{
+		{"邮箱格式错误", http.MethodPost, "/api/register", "", model.RegisterRequest{Username: "amy", Email: "not-an-email", Password: testPassword},
+			http.StatusBadRequest, apperr.InvalidParams.Code, []string{"email:email"}},
+		{"缺少字段", http.MethodPost, "/api/register", "", map[string]string{"username": "amy"},
+			http.StatusBadRequest, apperr.InvalidParams.Code, []string{"email:required", "password:required"}},
+		{"字段类型错误", http.MethodPost, "/api/login", "", `{"username": 1, "password": "x"}`,
+			http.StatusBadRequest, apperr.InvalidParams.Code, []string{"username:type"}},
+		{"JSON 格式错误", http.MethodPost, "/api/login", "", `{"username":`,
+			http.StatusBadRequest, apperr.InvalidParams.Code, nil},
+		{"密码策略", http.MethodPost, "/api/register", "", model.RegisterRequest{Username: "amy", Email: "amy@example.com", Password: "short"},
+			http.StatusBadRequest, apperr.PasswordPolicy.Code, []string{"password:min_length"}},
+		{"未认证", http.MethodGet, "/api/profile", "", nil,
+			http.StatusUnauthorized, apperr.MissingToken.Code, nil},
+		{"缺少权限", http.MethodGet, "/api/admin/users", userToken, nil,
+			http.StatusForbidden, apperr.PermissionDenied.Code, []string{"permission:permission"}},
+		{"接口不存在", http.MethodGet, "/api/missing", "", nil,
+			http.StatusNotFound, apperr.RouteNotFound.Code, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := s.do(t, tt.method, tt.path, tt.token, tt.body)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Fatalf("got %d/%d, want %d/%d (%+v)", status, resp.Code, tt.wantStatus, tt.wantCode, resp)
+			}
+			var details []string
+			for _, d := range resp.Details {
+				details = append(details, d.Field+":"+d.Rule)
+			}
+			if strings.Join(details, ",") != strings.Join(tt.wantDetails, ",") {
+				t.Errorf("details = %v, want %v", details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestAdminUserList(t *testing.T) {
+	s := newTestServer(t)
+	if _, err := s.admins.CreateAdmin(context.Background(), &model.CreateAdminRequest{Username: "root", Email: "root@example.com", Password: testPassword}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"amy", "bob", "cat"} {
+		s.register(t, name)
+	}
+	token := s.login(t, "root", testPassword).Token
+
+	status, resp := s.do(t, http.MethodGet, "/api/admin/users?page_size=2&sort_by=username&sort_order=asc", token, nil)
+	var page struct {
+		List  []model.UserResponse `json:"list"`
+		Total int64                `json:"total"`
+	}
+	if err := json.Unmarshal(resp.Data, &page); status != http.StatusOK || err != nil {
+		t.Fatalf("offset page: %d %+v", status, resp)
+	}
+	if page.Total != 4 || len(page.List) != 2 || page.List[0].Username != "amy" {
+		t.Errorf("offset page = %+v", page)
+	}
+
+	status, resp = s.do(t, http.MethodGet, "/api/admin/users?pagination=cursor&page_size=3", token, nil)
+	var cursorPage CursorPageData
+	if err := json.Unmarshal(resp.Data, &cursorPage); status != http.StatusOK || err != nil || !cursorPage.HasMore || cursorPage.NextCursor == "" {
+		t.Fatalf("cursor page: %d %+v", status, resp)
+	}
+	status, resp = s.do(t, http.MethodGet, "/api/admin/users?page_size=3&sort_order=asc&cursor="+cursorPage.NextCursor, token, nil)
+	if status != http.StatusBadRequest || resp.Code != apperr.InvalidParams.Code {
+		t.Errorf("cursor with changed order: %d %+v", status, resp)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantCode   int
+	}{
+		{"不支持的排序字段", "/api/admin/users?sort_by=password", http.StatusBadRequest, apperr.InvalidParams.Code},
+		{"无效游标", "/api/admin/users?cursor=%21%21", http.StatusBadRequest, apperr.InvalidCursor.Code},
+		{"无效 ID", "/api/admin/users/abc/disable", http.StatusBadRequest, apperr.InvalidID.Code},
+		{"用户不存在", "/api/admin/users/999/disable", http.StatusNotFound, apperr.UserNotFound.Code},
+	}
+	for _, tt := range tests {
+		method := http.MethodGet
+		if strings.HasSuffix(tt.path, "/disable") {
+			method = http.MethodPost
+		}
+		status, resp := s.do(t, method, tt.path, token, nil)
+		if status != tt.wantStatus || resp.Code != tt.wantCode {
+			t.Errorf("%s: got %d/%d, want %d/%d", tt.name, status, resp.Code, tt.wantStatus, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// internal/health/checks.go - 内置检查项
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"user-management/internal/migrate"
+
+	"gorm.io/gorm"
+)
+
+// DBPing 数据库连接检查
+func DBPing(db *gorm.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// Migrations 数据库结构检查，存在待执行的迁移时失败
+// 📌 运行期间有人执行 migrate down 或部署了更新的迁移文件时，实例会自动摘除
+func Migrations(migrator *migrate.Migrator) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return migrator.Check(ctx)
+	})
+}
+
+// errDiskSpaceUnsupported 当前平台无法获取磁盘剩余空间
+var errDiskSpaceUnsupported = errors.New("当前平台不支持磁盘空间检查")
+
+// DiskSpace dir 所在文件系统剩余可用空间不足 minFreeBytes 时失败
+// 📌 不支持 statfs 的平台跳过检查，视为正常
+func DiskSpace(dir string, minFreeBytes uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		free, err := freeBytes(dir)
+		if errors.Is(err, errDiskSpaceUnsupported) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if free < minFreeBytes {
+			return fmt.Errorf("%s 剩余空间 %d MB，低于 %d MB", dir, free>>20, minFreeBytes>>20)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+// internal/health/disk_other.go - 磁盘剩余空间（不支持的平台）
+package health
+
+func freeBytes(dir string) (uint64, error) {
+	return 0, errDiskSpaceUnsupported
+}
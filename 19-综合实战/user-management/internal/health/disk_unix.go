@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+// internal/health/disk_unix.go - 磁盘剩余空间（statfs）
+package health
+
+import "syscall"
+
+// freeBytes 返回非特权用户可用的剩余空间
+func freeBytes(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,156 @@
+// internal/health/health.go - 存活与就绪检查
+//
+// 📌 两种探针:
+//   - liveness (/livez): 进程能处理请求即为存活，不检查任何依赖，失败会被编排系统重启
+//   - readiness (/readyz): 依次判断已注册的依赖检查，任一失败则不接收流量，但不重启
+//
+// 📌 收到退出信号后先调用 SetShuttingDown，就绪检查立即返回未就绪，
+// 负载均衡摘除实例后再关闭 HTTP 服务，避免新请求打到正在退出的进程
+//
+// 📌 探针不经过认证，响应中只给出通用原因，具体错误（可能含数据库地址、驱动信息）只写日志
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"user-management/internal/logging"
+
+	"go.uber.org/zap"
+)
+
+// 检查结果状态
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// 检查失败的通用原因
+const (
+	ReasonTimeout = "timeout"
+	ReasonFailed  = "check_failed"
+)
+
+// Checker 依赖检查，返回 nil 表示正常
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的 Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"` // ReasonTimeout / ReasonFailed
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Ready 是否就绪
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Health 就绪检查注册表
+type Health struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checkers     []namedChecker
+	shuttingDown atomic.Bool
+}
+
+// New timeout 为单项检查的超时，<= 0 时不限制
+func New(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Register 注册检查项，同名检查项会被覆盖
+func (h *Health) Register(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.checkers {
+		if h.checkers[i].name == name {
+			h.checkers[i].checker = checker
+			return
+		}
+	}
+	h.checkers = append(h.checkers, namedChecker{name: name, checker: checker})
+}
+
+// SetShuttingDown 标记进程正在退出，之后的就绪检查均返回未就绪
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Readiness 并发执行全部检查项
+func (h *Health) Readiness(ctx context.Context) *Report {
+	if h.shuttingDown.Load() {
+		return &Report{Status: StatusShuttingDown}
+	}
+
+	h.mu.RLock()
+	checkers := append([]namedChecker(nil), h.checkers...)
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, nc := range checkers {
+		wg.Add(1)
+		go func(i int, nc namedChecker) {
+			defer wg.Done()
+			results[i] = h.run(ctx, nc.name, nc.checker)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checkers))}
+	for i, nc := range checkers {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (h *Health) run(ctx context.Context, name string, checker Checker) CheckResult {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = ReasonFailed
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = ReasonTimeout
+		}
+		logging.FromContext(ctx).Warn("就绪检查失败", zap.String("check", name), zap.Error(err))
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := CheckerFunc(func(ctx context.Context) error { return nil })
+	failing := CheckerFunc(func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") })
+	slow := CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name       string
+		checkers   map[string]Checker
+		wantStatus string
+		wantErrors map[string]string
+	}{
+		{"没有检查项", nil, StatusOK, nil},
+		{"全部正常", map[string]Checker{"db": ok, "disk": ok}, StatusOK, map[string]string{"db": "", "disk": ""}},
+		{"检查失败不暴露原因", map[string]Checker{"db": failing, "disk": ok}, StatusFail, map[string]string{"db": ReasonFailed, "disk": ""}},
+		{"检查超时", map[string]Checker{"db": slow}, StatusFail, map[string]string{"db": ReasonTimeout}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(50 * time.Millisecond)
+			for name, c := range tt.checkers {
+				h.Register(name, c)
+			}
+			report := h.Readiness(context.Background())
+			if report.Status != tt.wantStatus || report.Ready() != (tt.wantStatus == StatusOK) {
+				t.Fatalf("status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			for name, want := range tt.wantErrors {
+				if got := report.Checks[name].Error; got != want {
+					t.Errorf("%s: error = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessShuttingDown(t *testing.T) {
+	var calls atomic.Int32
+	h := New(time.Second)
+	h.Register("db", CheckerFunc(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	if report := h.Readiness(context.Background()); !report.Ready() {
+		t.Fatalf("before shutdown: status = %s, want ok", report.Status)
+	}
+
+	h.SetShuttingDown()
+	// 退出期间依赖仍然正常，但不再接收流量，也不再执行检查
+	for i := 0; i < 2; i++ {
+		report := h.Readiness(context.Background())
+		if report.Status != StatusShuttingDown || report.Ready() {
+			t.Fatalf("after shutdown: status = %s, want %s", report.Status, StatusShuttingDown)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("checker called %d times, want 1", n)
+	}
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	h := New(0)
+	h.Register("db", CheckerFunc(func(ctx context.Context) error { return errors.New("down") }))
+	h.Register("db", CheckerFunc(func(ctx context.Context) error { return nil }))
+
+	report := h.Readiness(context.Background())
+	if !report.Ready() || len(report.Checks) != 1 {
+		t.Fatalf("report = %+v, want one passing check", report)
+	}
+}
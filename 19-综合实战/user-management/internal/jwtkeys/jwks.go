@@ -0,0 +1,53 @@
+// internal/jwtkeys/jwks.go - JWKS 公钥发布 (RFC 7517)
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK 单个公钥
+type JWK struct {
+	Kty string `json:"kty"`           // RSA / OKP
+	Kid string `json:"kid"`           // 密钥 ID，对应 token 头部的 kid
+	Use string `json:"use"`           // sig
+	Alg string `json:"alg"`           // RS256 / EdDSA
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 指数
+	Crv string `json:"crv,omitempty"` // Ed25519
+	X   string `json:"x,omitempty"`   // Ed25519 公钥
+}
+
+// JWKS 公钥集合
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 返回全部非对称密钥的公钥（包括仅用于校验的旧密钥）
+// 📌 HS256 共享密钥不能公开，兼容模式下返回空集合
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.ordered))}
+	for _, key := range ks.ordered {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeB64(pub.N.Bytes())
+			jwk.E = encodeB64(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeB64(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func encodeB64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,251 @@
+// internal/jwtkeys/keyset.go - JWT 签名密钥集
+//
+// 📌 支持三种算法:
+//   - HS256: 共享密钥（未配置 keys 时的兼容模式，不对外发布）
+//   - RS256: RSA 私钥签名，公钥校验
+//   - EdDSA: Ed25519 私钥签名，公钥校验
+//
+// 📌 多密钥轮换: token 头部携带 kid，校验时按 kid 查找公钥
+// 📌 只有 active_kid 用于签名，其余密钥仅用于校验旧 token
+// 📌 头部 typ 区分 token 用途，同一密钥签发的不同类型 token 不能互相冒用
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"user-management/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultIssuer = "user-management"
+
+// PlaceholderSecret config.yaml 附带的示例 HS256 密钥，仅供本地开发
+// 📌 仓库公开，任何人都能用它伪造 token
+const PlaceholderSecret = "your-256-bit-secret-key-here!!!"
+
+// minSecretBytes HS256 密钥至少与哈希输出等长（RFC 7518 3.2）
+const minSecretBytes = 32
+
+var (
+	ErrUnknownKey   = errors.New("未知的签名密钥")
+	ErrTokenType    = errors.New("token 类型不匹配")
+	ErrInsecureHMAC = errors.New("jwt.secret 是示例值或短于 32 字节，生产环境请改用随机密钥或配置 jwt.keys")
+)
+
+// Key 单个签名密钥
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{} // 仅配置私钥时非空
+	verifyKey interface{}
+	public    crypto.PublicKey // 非对称密钥的公钥，用于发布 JWKS
+}
+
+// KeySet 签名密钥集
+type KeySet struct {
+	issuer  string
+	active  *Key
+	keys    map[string]*Key
+	ordered []*Key // 按配置顺序，JWKS 输出保持稳定
+	parser  *jwt.Parser
+}
+
+// Load 根据配置加载密钥集
+func Load(cfg *config.JWTConfig) (*KeySet, error) {
+	ks := &KeySet{
+		issuer: cfg.Issuer,
+		keys:   make(map[string]*Key),
+	}
+	if ks.issuer == "" {
+		ks.issuer = defaultIssuer
+	}
+
+	if len(cfg.Keys) == 0 {
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt.secret 和 jwt.keys 不能同时为空")
+		}
+		ks.active = &Key{
+			Method:    jwt.SigningMethodHS256,
+			signKey:   []byte(cfg.Secret),
+			verifyKey: []byte(cfg.Secret),
+		}
+		ks.keys[""] = ks.active
+		ks.ordered = append(ks.ordered, ks.active)
+	} else {
+		for _, keyCfg := range cfg.Keys {
+			key, err := loadKey(keyCfg)
+			if err != nil {
+				return nil, fmt.Errorf("加载密钥 %q 失败: %w", keyCfg.KID, err)
+			}
+			if _, exists := ks.keys[key.ID]; exists {
+				return nil, fmt.Errorf("密钥 ID 重复: %q", key.ID)
+			}
+			ks.keys[key.ID] = key
+			ks.ordered = append(ks.ordered, key)
+		}
+
+		active, ok := ks.keys[cfg.ActiveKID]
+		if !ok {
+			return nil, fmt.Errorf("active_kid %q 未在 keys 中配置", cfg.ActiveKID)
+		}
+		if active.signKey == nil {
+			return nil, fmt.Errorf("active_kid %q 未配置私钥，无法签名", cfg.ActiveKID)
+		}
+		ks.active = active
+	}
+
+	// 📌 只接受密钥集中出现过的算法，防止 alg=none 或 RS256/HS256 混淆攻击
+	methods := make([]string, 0, len(ks.ordered))
+	for _, key := range ks.ordered {
+		methods = append(methods, key.Method.Alg())
+	}
+	ks.parser = jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(ks.issuer),
+		jwt.WithExpirationRequired(),
+	)
+
+	return ks, nil
+}
+
+// CheckProduction 生产环境（server.mode=release）启动前检查: HS256 兼容模式下拒绝示例密钥和过短的密钥
+// 📌 开发环境不调用，config.yaml 开箱即用
+func CheckProduction(cfg *config.JWTConfig) error {
+	if len(cfg.Keys) > 0 {
+		return nil
+	}
+	if cfg.Secret == PlaceholderSecret || len(cfg.Secret) < minSecretBytes {
+		return ErrInsecureHMAC
+	}
+	return nil
+}
+
+// Issuer 返回签发者（iss）
+func (ks *KeySet) Issuer() string {
+	return ks.issuer
+}
+
+// Sign 使用当前密钥签名，头部写入 kid 和 typ
+func (ks *KeySet) Sign(claims jwt.Claims, typ string) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["typ"] = typ
+	if ks.active.ID != "" {
+		token.Header["kid"] = ks.active.ID
+	}
+	return token.SignedString(ks.active.signKey)
+}
+
+// Parse 校验签名、算法、签发者、过期时间和头部 typ，并解析到 claims
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims, typ string) (*jwt.Token, error) {
+	token, err := ks.parser.ParseWithClaims(tokenString, claims, ks.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if headerTyp, _ := token.Header["typ"].(string); headerTyp != typ {
+		return nil, ErrTokenType
+	}
+	return token, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	// 同一个 kid 只能对应一种算法
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnknownKey
+	}
+	return key.verifyKey, nil
+}
+
+// loadKey 从 PEM 文件加载密钥，算法由密钥类型决定
+func loadKey(cfg config.JWTKeyConfig) (*Key, error) {
+	if cfg.KID == "" {
+		return nil, errors.New("kid 不能为空")
+	}
+
+	key := &Key{ID: cfg.KID}
+	switch {
+	case cfg.PrivateKeyFile != "":
+		block, err := readPEM(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		switch k := private.(type) {
+		case *rsa.PrivateKey:
+			key.signKey, key.public = k, &k.PublicKey
+		case ed25519.PrivateKey:
+			key.signKey, key.public = k, k.Public()
+		default:
+			return nil, fmt.Errorf("不支持的私钥类型 %T", private)
+		}
+	case cfg.PublicKeyFile != "":
+		block, err := readPEM(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, err
+		}
+		key.public = public
+	default:
+		return nil, errors.New("private_key_file 和 public_key_file 至少配置一个")
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA 密钥长度不能小于 2048 位")
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.verifyKey = pub
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.verifyKey = pub
+	default:
+		return nil, fmt.Errorf("不支持的公钥类型 %T", key.public)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s 不是有效的 PEM 文件", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey 支持 PKCS#8（openssl genpkey 默认格式）和 PKCS#1 RSA 私钥
+func parsePrivateKey(block *pem.Block) (interface{}, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// parsePublicKey 支持 PKIX（openssl pkey -pubout 默认格式）和 PKCS#1 RSA 公钥
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
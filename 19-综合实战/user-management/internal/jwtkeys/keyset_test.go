@@ -0,0 +1,375 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testTyp = "at+jwt"
+
+// testKeys 测试用的 PEM 文件
+type testKeys struct {
+	dir          string
+	rsa          *rsa.PrivateKey
+	ed25519      ed25519.PrivateKey
+	rsaPriv      string // PKCS#8 私钥
+	rsaPKCS1Priv string // PKCS#1 私钥
+	rsaPub       string // PKIX 公钥
+	rsaPKCS1Pub  string // PKCS#1 公钥
+	rsaPubPEM    []byte // PKIX 公钥 PEM 原文，用于构造算法混淆攻击
+	edPriv       string
+	edPub        string
+	weakRSA      string // 1024 位
+	notPEM       string
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	k := &testKeys{dir: t.TempDir()}
+
+	var err error
+	if k.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if _, k.ed25519, err = ed25519.GenerateKey(rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k.rsaPriv = k.writePKCS8(t, "rsa.pem", k.rsa)
+	k.rsaPKCS1Priv = k.write(t, "rsa-pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(k.rsa))
+	k.rsaPub = k.writePKIX(t, "rsa.pub.pem", &k.rsa.PublicKey)
+	k.rsaPKCS1Pub = k.write(t, "rsa-pkcs1.pub.pem", "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&k.rsa.PublicKey))
+	k.edPriv = k.writePKCS8(t, "ed25519.pem", k.ed25519)
+	k.edPub = k.writePKIX(t, "ed25519.pub.pem", k.ed25519.Public())
+	k.weakRSA = k.writePKCS8(t, "weak.pem", weak)
+	k.notPEM = filepath.Join(k.dir, "not.pem")
+	if err := os.WriteFile(k.notPEM, []byte("not a pem file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(k.rsaPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.rsaPubPEM = raw
+	return k
+}
+
+func (k *testKeys) write(t *testing.T, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(k.dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (k *testKeys) writePKCS8(t *testing.T, name string, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k.write(t, name, "PRIVATE KEY", der)
+}
+
+func (k *testKeys) writePKIX(t *testing.T, name string, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k.write(t, name, "PUBLIC KEY", der)
+}
+
+func mustLoad(t *testing.T, cfg *config.JWTConfig) *KeySet {
+	t.Helper()
+	ks, err := Load(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func newClaims(ttl time.Duration) *jwt.RegisteredClaims {
+	now := time.Now()
+	return &jwt.RegisteredClaims{
+		Subject:   "1",
+		Issuer:    defaultIssuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+func TestLoad(t *testing.T) {
+	k := newTestKeys(t)
+
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		wantAlg string
+		wantErr string
+	}{
+		{"HS256 兼容模式", config.JWTConfig{Secret: strings.Repeat("s", 32)}, "HS256", ""},
+		{"RSA PKCS#8 私钥", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.rsaPriv}}}, "RS256", ""},
+		{"RSA PKCS#1 私钥", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.rsaPKCS1Priv}}}, "RS256", ""},
+		{"Ed25519 私钥", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.edPriv}}}, "EdDSA", ""},
+		{"旧密钥只有公钥", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.rsaPriv}, {KID: "b", PublicKeyFile: k.edPub}}}, "RS256", ""},
+		{"secret 和 keys 都为空", config.JWTConfig{}, "", "不能同时为空"},
+		{"kid 为空", config.JWTConfig{ActiveKID: "", Keys: []config.JWTKeyConfig{{PrivateKeyFile: k.rsaPriv}}}, "", "kid 不能为空"},
+		{"kid 重复", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.rsaPriv}, {KID: "a", PrivateKeyFile: k.edPriv}}}, "", "密钥 ID 重复"},
+		{"active_kid 未配置", config.JWTConfig{ActiveKID: "b", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.rsaPriv}}}, "", "未在 keys 中配置"},
+		{"active_kid 只有公钥", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PublicKeyFile: k.rsaPub}}}, "", "未配置私钥"},
+		{"未配置密钥文件", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a"}}}, "", "至少配置一个"},
+		{"非 PEM 文件", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.notPEM}}}, "", "不是有效的 PEM 文件"},
+		{"文件不存在", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: filepath.Join(k.dir, "missing.pem")}}}, "", "no such file"},
+		{"RSA 私钥小于 2048 位", config.JWTConfig{ActiveKID: "a", Keys: []config.JWTKeyConfig{{KID: "a", PrivateKeyFile: k.weakRSA}}}, "", "2048"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := Load(&tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ks.active.Method.Alg(); got != tt.wantAlg {
+				t.Errorf("alg = %s, want %s", got, tt.wantAlg)
+			}
+			if ks.Issuer() != defaultIssuer {
+				t.Errorf("Issuer = %q, want %q", ks.Issuer(), defaultIssuer)
+			}
+		})
+	}
+}
+
+func TestLoadPublicKeyFormats(t *testing.T) {
+	k := newTestKeys(t)
+	signer := mustLoad(t, &config.JWTConfig{ActiveKID: "old", Keys: []config.JWTKeyConfig{{KID: "old", PrivateKeyFile: k.rsaPriv}}})
+	token, err := signer.Sign(newClaims(time.Minute), testTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pub := range []string{k.rsaPub, k.rsaPKCS1Pub} {
+		ks := mustLoad(t, &config.JWTConfig{ActiveKID: "new", Keys: []config.JWTKeyConfig{
+			{KID: "new", PrivateKeyFile: k.edPriv},
+			{KID: "old", PublicKeyFile: pub},
+		}})
+		if _, err := ks.Parse(token, &jwt.RegisteredClaims{}, testTyp); err != nil {
+			t.Errorf("%s: %v", filepath.Base(pub), err)
+		}
+	}
+}
+
+func TestSignParseRoundTrip(t *testing.T) {
+	k := newTestKeys(t)
+
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		wantAlg string
+		wantKID string
+	}{
+		{"HS256", config.JWTConfig{Secret: strings.Repeat("s", 32)}, "HS256", ""},
+		{"RS256", config.JWTConfig{ActiveKID: "r", Keys: []config.JWTKeyConfig{{KID: "r", PrivateKeyFile: k.rsaPriv}}}, "RS256", "r"},
+		{"EdDSA", config.JWTConfig{ActiveKID: "e", Keys: []config.JWTKeyConfig{{KID: "e", PrivateKeyFile: k.edPriv}}}, "EdDSA", "e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := mustLoad(t, &tt.cfg)
+			token, err := ks.Sign(newClaims(time.Minute), testTyp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var claims jwt.RegisteredClaims
+			parsed, err := ks.Parse(token, &claims, testTyp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %s, want %s", parsed.Method.Alg(), tt.wantAlg)
+			}
+			if kid, _ := parsed.Header["kid"].(string); kid != tt.wantKID {
+				t.Errorf("kid = %q, want %q", kid, tt.wantKID)
+			}
+			if claims.Subject != "1" {
+				t.Errorf("sub = %q, want 1", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	k := newTestKeys(t)
+	ks := mustLoad(t, &config.JWTConfig{ActiveKID: "r", Keys: []config.JWTKeyConfig{
+		{KID: "r", PrivateKeyFile: k.rsaPriv},
+		{KID: "e", PrivateKeyFile: k.edPriv},
+	}})
+
+	// sign 用指定算法和密钥手工签名，模拟伪造的 token
+	sign := func(method jwt.SigningMethod, key interface{}, kid, typ string, claims jwt.Claims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["typ"] = typ
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	valid := newClaims(time.Minute)
+	noExp := newClaims(time.Minute)
+	noExp.ExpiresAt = nil
+	wrongIssuer := newClaims(time.Minute)
+	wrongIssuer.Issuer = "someone-else"
+
+	tests := []struct {
+		name    string
+		token   string
+		typ     string
+		wantErr error
+	}{
+		{"typ 不匹配", sign(jwt.SigningMethodRS256, k.rsa, "r", "mfa-pending+jwt", valid), testTyp, ErrTokenType},
+		{"缺少 typ", sign(jwt.SigningMethodRS256, k.rsa, "r", "", valid), testTyp, ErrTokenType},
+		{"未知 kid", sign(jwt.SigningMethodRS256, k.rsa, "x", testTyp, valid), testTyp, ErrUnknownKey},
+		{"缺少 kid", sign(jwt.SigningMethodRS256, k.rsa, "", testTyp, valid), testTyp, ErrUnknownKey},
+		{"kid 与算法不符", sign(jwt.SigningMethodEdDSA, k.ed25519, "r", testTyp, valid), testTyp, ErrUnknownKey},
+		{"用 RSA 公钥作为 HS256 密钥", sign(jwt.SigningMethodHS256, k.rsaPubPEM, "r", testTyp, valid), testTyp, jwt.ErrTokenSignatureInvalid},
+		{"alg=none", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "r", testTyp, valid), testTyp, jwt.ErrTokenSignatureInvalid},
+		{"已过期", sign(jwt.SigningMethodRS256, k.rsa, "r", testTyp, newClaims(-time.Minute)), testTyp, jwt.ErrTokenExpired},
+		{"缺少 exp", sign(jwt.SigningMethodRS256, k.rsa, "r", testTyp, noExp), testTyp, jwt.ErrTokenRequiredClaimMissing},
+		{"签发者不符", sign(jwt.SigningMethodRS256, k.rsa, "r", testTyp, wrongIssuer), testTyp, jwt.ErrTokenInvalidIssuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ks.Parse(tt.token, &jwt.RegisteredClaims{}, tt.typ)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	k := newTestKeys(t)
+	before := mustLoad(t, &config.JWTConfig{ActiveKID: "2026-04", Keys: []config.JWTKeyConfig{
+		{KID: "2026-04", PrivateKeyFile: k.rsaPriv},
+	}})
+	oldToken, err := before.Sign(newClaims(time.Minute), testTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新密钥上线，旧密钥只保留公钥
+	after := mustLoad(t, &config.JWTConfig{ActiveKID: "2026-10", Keys: []config.JWTKeyConfig{
+		{KID: "2026-10", PrivateKeyFile: k.edPriv},
+		{KID: "2026-04", PublicKeyFile: k.rsaPub},
+	}})
+	if _, err := after.Parse(oldToken, &jwt.RegisteredClaims{}, testTyp); err != nil {
+		t.Fatalf("old token after rotation: %v", err)
+	}
+
+	newToken, err := after.Sign(newClaims(time.Minute), testTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := after.Parse(newToken, &jwt.RegisteredClaims{}, testTyp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := parsed.Header["kid"]; kid != "2026-10" || parsed.Method.Alg() != "EdDSA" {
+		t.Errorf("new token kid = %v alg = %s, want 2026-10 EdDSA", kid, parsed.Method.Alg())
+	}
+
+	// 旧密钥移除后旧 token 失效
+	removed := mustLoad(t, &config.JWTConfig{ActiveKID: "2026-10", Keys: []config.JWTKeyConfig{
+		{KID: "2026-10", PrivateKeyFile: k.edPriv},
+	}})
+	if _, err := removed.Parse(oldToken, &jwt.RegisteredClaims{}, testTyp); err == nil {
+		t.Fatal("old token accepted after its key was removed")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	k := newTestKeys(t)
+
+	hmac := mustLoad(t, &config.JWTConfig{Secret: strings.Repeat("s", 32)})
+	if keys := hmac.JWKS().Keys; len(keys) != 0 {
+		t.Fatalf("HS256 JWKS = %+v, want empty", keys)
+	}
+
+	ks := mustLoad(t, &config.JWTConfig{ActiveKID: "e", Keys: []config.JWTKeyConfig{
+		{KID: "e", PrivateKeyFile: k.edPriv},
+		{KID: "r", PublicKeyFile: k.rsaPub},
+	}})
+	keys := ks.JWKS().Keys
+	if len(keys) != 2 {
+		t.Fatalf("len(JWKS) = %d, want 2", len(keys))
+	}
+
+	ed := keys[0]
+	if ed.Kid != "e" || ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.Use != "sig" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(ed.X); string(x) != string(k.ed25519.Public().(ed25519.PublicKey)) {
+		t.Error("Ed25519 JWK x does not match public key")
+	}
+
+	r := keys[1]
+	if r.Kid != "r" || r.Kty != "RSA" || r.Alg != "RS256" || r.Use != "sig" {
+		t.Errorf("RSA JWK = %+v", r)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(r.N)
+	e, _ := base64.RawURLEncoding.DecodeString(r.E)
+	if new(big.Int).SetBytes(n).Cmp(k.rsa.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != k.rsa.E {
+		t.Error("RSA JWK n/e does not match public key")
+	}
+	if r.X != "" || r.Crv != "" || ed.N != "" || ed.E != "" {
+		t.Error("JWK contains fields of another key type")
+	}
+}
+
+func TestCheckProduction(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.JWTConfig
+		wantErr bool
+	}{
+		{"示例密钥", config.JWTConfig{Secret: PlaceholderSecret}, true},
+		{"过短的密钥", config.JWTConfig{Secret: "short-secret"}, true},
+		{"随机密钥", config.JWTConfig{Secret: strings.Repeat("x", 32)}, false},
+		{"非对称密钥不检查 secret", config.JWTConfig{Secret: PlaceholderSecret, Keys: []config.JWTKeyConfig{{KID: "a"}}}, false},
+	}
+	for _, tt := range tests {
+		if err := CheckProduction(&tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
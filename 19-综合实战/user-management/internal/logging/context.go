@@ -0,0 +1,40 @@
+// internal/logging/context.go - 请求级日志上下文
+//
+// 📌 RequestIDMiddleware 把带 request_id 字段的 *zap.Logger 放入 c.Request.Context()，
+// handler → service → repository（含 GORM SQL 日志）通过 FromContext 取出，同一请求的日志可按 ID 关联
+// 📌 ctx 中没有 logger 时（命令行、启动阶段）回退到 zap.L()
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithLogger 返回携带 logger 的 ctx
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 取出请求级 logger，没有时返回全局 logger
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.L()
+}
+
+// WithRequestID 返回携带请求 ID 的 ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 取出请求 ID，没有时返回空串
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,81 @@
+// internal/logging/logger.go - 日志初始化
+//
+// 📌 输出:
+//   - 控制台: 始终输出到 stdout，log.format 选择 console（本地开发）或 json（容器日志采集）
+//   - 文件: 配置了 log.filename 时额外输出 JSON 到文件，由 lumberjack 按大小轮转
+//
+// 📌 两个输出共享同一个 zap.AtomicLevel，运行时修改级别立即对两者生效
+package logging
+
+import (
+	"fmt"
+	"os"
+	"user-management/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// New 按配置创建 logger，返回的 AtomicLevel 用于运行时调整级别
+func New(cfg *config.LogConfig) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevel()
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, level, fmt.Errorf("无效的日志级别 %q: %w", cfg.Level, err)
+		}
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	var consoleEncoder zapcore.Encoder
+	switch cfg.Format {
+	case "", FormatConsole:
+		colored := encoderConfig
+		colored.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		consoleEncoder = zapcore.NewConsoleEncoder(colored)
+	case FormatJSON:
+		consoleEncoder = zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		return nil, level, fmt.Errorf("无效的日志格式 %q（可选: console, json）", cfg.Format)
+	}
+
+	cores := []zapcore.Core{
+		zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), level),
+	}
+	if cfg.Filename != "" {
+		fileWriter := &lumberjack.Logger{
+			Filename:   cfg.Filename,
+			MaxSize:    cfg.MaxSize,    // MB
+			MaxBackups: cfg.MaxBackups, // 个
+			MaxAge:     cfg.MaxAge,     // 天
+			Compress:   cfg.Compress,
+		}
+		// 📌 文件日志固定 JSON，便于检索和采集
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(fileWriter), level))
+	}
+
+	logger := zap.New(zapcore.NewTee(cores...),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+	return logger, level, nil
+}
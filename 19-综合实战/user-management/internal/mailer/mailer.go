@@ -0,0 +1,178 @@
+// internal/mailer/mailer.go - 邮件发送
+//
+// 📌 Mailer 接口与具体实现解耦:
+//   - SMTPMailer: 生产环境通过 SMTP 发送
+//   - LogMailer: 本地开发写入日志/文件，无需邮件服务器
+//   - AsyncMailer: 异步发送，避免接口耗时暴露"邮箱是否存在"
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"user-management/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// New 根据配置创建 Mailer
+func New(cfg *config.MailConfig, logger *zap.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		if cfg.LogBody {
+			logger.Warn("mail.log_body 已开启，邮件正文（含有效令牌）将写入日志，仅限本地开发使用")
+		}
+		return NewLogMailer(cfg.LogFile, cfg.LogBody, logger), nil
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件驱动: %s", cfg.Driver)
+	}
+}
+
+// ==================== SMTP ====================
+
+// SMTPMailer SMTP 实现
+// 📌 net/smtp 在服务器支持时会自动升级 STARTTLS
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *config.MailConfig) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, buildMessage(m.from, to, subject, body))
+}
+
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// ==================== 日志/文件 ====================
+
+// tokenParam 邮件链接中的令牌参数
+var tokenParam = regexp.MustCompile(`(token=)[^&\s]+`)
+
+// LogMailer 将邮件写入日志，配置了文件时追加写入文件
+// 📌 链接中的令牌可直接重置密码/验证邮箱，默认替换为 ***；
+// logBody 为 true 时才记录原文（仅限本地开发，需要点击链接调试时开启）
+type LogMailer struct {
+	filename string
+	logBody  bool
+	logger   *zap.Logger
+	mu       sync.Mutex
+}
+
+func NewLogMailer(filename string, logBody bool, logger *zap.Logger) *LogMailer {
+	return &LogMailer{filename: filename, logBody: logBody, logger: logger}
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	if !m.logBody {
+		body = tokenParam.ReplaceAllString(body, "${1}***")
+	}
+
+	fields := []zap.Field{zap.String("to", to), zap.String("subject", subject)}
+	if m.logBody {
+		fields = append(fields, zap.String("body", body))
+	}
+	m.logger.Info("邮件（未实际发送）", fields...)
+
+	if m.filename == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "==== %s ====\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), to, subject, body)
+	return err
+}
+
+// ==================== 异步 ====================
+
+// AsyncMailer 在后台 goroutine 中发送，失败只记录日志
+// 📌 退出前调用 Wait 等待发送中的邮件，避免重置/验证邮件丢失
+type AsyncMailer struct {
+	next   Mailer
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+func NewAsyncMailer(next Mailer, logger *zap.Logger) *AsyncMailer {
+	return &AsyncMailer{next: next, logger: logger}
+}
+
+func (m *AsyncMailer) Send(to, subject, body string) error {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := m.next.Send(to, subject, body); err != nil {
+			m.logger.Error("邮件发送失败",
+				zap.String("to", to),
+				zap.String("subject", subject),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// Wait 等待全部发送中的邮件完成，ctx 到期时返回 ctx.Err()
+func (m *AsyncMailer) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,144 @@
+// internal/metrics/metrics.go - Prometheus 指标
+//
+// 📌 指标注册在独立的 prometheus.Registry 上（不使用全局 DefaultRegisterer），
+// 测试中可以 New() 一个实例，用 httptest 请求 Handler() 抓取
+// 📌 HTTP 指标按 Gin 路由模板（/api/admin/users/:id）打标签，而不是原始路径，避免标签基数爆炸
+//
+// 指标:
+//
+//	user_management_http_requests_total{method,route,status}
+//	user_management_http_request_duration_seconds{method,route}
+//	user_management_http_requests_in_flight{method,route}
+//	user_management_db_query_duration_seconds{operation,table}
+//	user_management_auth_logins_total{result}
+//	user_management_auth_registrations_total
+//	user_management_auth_lockouts_total{scope}
+//	go_sql_*{db_name}  连接池状态
+//	go_* / process_*    运行时与进程指标
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "user_management"
+
+// unmatchedRoute 未匹配到路由的请求统一使用的标签值
+const unmatchedRoute = "unmatched"
+
+// Metrics 指标集合
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight *prometheus.GaugeVec
+	dbDuration   *prometheus.HistogramVec
+
+	logins        *prometheus.CounterVec
+	registrations prometheus.Counter
+	lockouts      *prometheus.CounterVec
+}
+
+// New 创建指标集合，并注册 Go 运行时与进程指标
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP 请求总数",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP 请求耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "正在处理的 HTTP 请求数",
+		}, []string{"method", "route"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "数据库操作耗时",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "登录次数，result 为 success / failure",
+		}, []string{"result"}),
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_registrations_total",
+			Help:      "注册成功的用户数",
+		}),
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_lockouts_total",
+			Help:      "登录失败次数超限触发的锁定次数，scope 为 user / ip",
+		}, []string{"scope"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.httpInFlight, m.dbDuration,
+		m.logins, m.registrations, m.lockouts,
+	)
+	return m
+}
+
+// Handler 以 Prometheus 文本格式输出全部指标
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware HTTP 指标中间件，需注册在 RecoveryMiddleware 之前，panic 的请求才能记为 500
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		inFlight := m.httpInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		m.httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ==================== service.AuthEvents ====================
+
+func (m *Metrics) LoginSucceeded() {
+	m.logins.WithLabelValues("success").Inc()
+}
+
+func (m *Metrics) LoginFailed() {
+	m.logins.WithLabelValues("failure").Inc()
+}
+
+func (m *Metrics) UserRegistered() {
+	m.registrations.Inc()
+}
+
+func (m *Metrics) LockoutTriggered(scope string) {
+	m.lockouts.WithLabelValues(scope).Inc()
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// scrape 通过 httptest 请求 /metrics，返回文本格式的指标
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetricsScrape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterDB(db); err != nil {
+		t.Fatal(err)
+	}
+
+	type item struct {
+		ID   uint
+		Name string
+	}
+
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/api/admin/users/:id", func(c *gin.Context) {
+		if err := db.AutoMigrate(&item{}); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		var items []item
+		db.Find(&items)
+		c.Status(http.StatusOK)
+	})
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
+	for _, path := range []string{"/api/admin/users/1", "/api/admin/users/2", "/no-such-route"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	m.LoginSucceeded()
+	m.LoginFailed()
+	m.LoginFailed()
+	m.UserRegistered()
+	m.LockoutTriggered("ip")
+
+	body := scrape(t, r)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"按路由模板计数", `user_management_http_requests_total{method="GET",route="/api/admin/users/:id",status="200"} 2`},
+		{"未匹配路由", `user_management_http_requests_total{method="GET",route="unmatched",status="404"} 1`},
+		{"耗时直方图", `user_management_http_request_duration_seconds_count{method="GET",route="/api/admin/users/:id"} 2`},
+		{"请求结束后在途数归零", `user_management_http_requests_in_flight{method="GET",route="/api/admin/users/:id"} 0`},
+		{"数据库查询耗时", `user_management_db_query_duration_seconds_count{operation="query",table="items"} 2`},
+		{"连接池状态", `go_sql_max_open_connections{db_name="sqlite"}`},
+		{"登录成功", `user_management_auth_logins_total{result="success"} 1`},
+		{"登录失败", `user_management_auth_logins_total{result="failure"} 2`},
+		{"注册", `user_management_auth_registrations_total 1`},
+		{"锁定", `user_management_auth_lockouts_total{scope="ip"} 1`},
+		{"运行时指标", `go_goroutines`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(body, tt.want) {
+			t.Errorf("%s: missing %q", tt.name, tt.want)
+		}
+	}
+
+	// 原始路径不能出现在标签中
+	if strings.Contains(body, `route="/api/admin/users/1"`) {
+		t.Error("raw path used as route label")
+	}
+}
@@ -2,44 +2,50 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
-	"user-management/internal/config"
+	"user-management/internal/apperr"
 	"user-management/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+// passwordChangeAllowed 必须修改密码时仍可访问的接口
+var passwordChangeAllowed = map[string]bool{
+	"GET /api/profile":  true,
+	"PUT /api/password": true,
+	"POST /api/logout":  true,
+}
+
 // AuthMiddleware JWT 认证中间件
-func AuthMiddleware(jwtConfig *config.JWTConfig) gin.HandlerFunc {
+// 📌 除校验签名和过期时间外，还会确认 token 所属会话未被吊销
+func AuthMiddleware(tokenService service.TokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "缺少认证头",
-			})
+			c.Error(apperr.MissingToken)
 			c.Abort()
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "认证格式错误",
-			})
+			c.Error(apperr.MalformedToken)
 			c.Abort()
 			return
 		}
 
-		claims, err := parseToken(parts[1], jwtConfig.Secret)
+		claims, err := tokenService.ParseAccessToken(c.Request.Context(), parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "token 无效或已过期",
-			})
+			// 📌 token 无效时已是 InvalidToken（401）；查询会话失败、超时、请求取消等原样交给 ErrorMiddleware（5xx），
+			// 否则数据库故障期间所有用户都会被当作登录失效而退出
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// 📌 必须修改初始密码的账号只能访问少数接口
+		if claims.PasswordChangeRequired && !passwordChangeAllowed[c.Request.Method+" "+c.FullPath()] {
+			c.Error(apperr.PasswordChangeRequired)
 			c.Abort()
 			return
 		}
@@ -47,37 +53,10 @@ func AuthMiddleware(jwtConfig *config.JWTConfig) gin.HandlerFunc {
 		// 存入 Context
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-
-		c.Next()
-	}
-}
+		c.Set("roles", claims.Roles)
+		c.Set("permissions", claims.Permissions)
+		c.Set("sessionID", claims.SessionID)
 
-// AdminMiddleware 管理员权限中间件
-func AdminMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "需要管理员权限",
-			})
-			c.Abort()
-			return
-		}
 		c.Next()
 	}
 }
-
-func parseToken(tokenString, secret string) (*service.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &service.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	if claims, ok := token.Claims.(*service.Claims); ok && token.Valid {
-		return claims, nil
-	}
-	return nil, jwt.ErrSignatureInvalid
-}
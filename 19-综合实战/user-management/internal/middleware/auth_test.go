@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-management/internal/apperr"
+	"user-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubTokens 只实现 ParseAccessToken，其他方法未使用
+type stubTokens struct {
+	service.TokenService
+	claims *service.Claims
+	err    error
+}
+
+func (s *stubTokens) ParseAccessToken(ctx context.Context, tokenString string) (*service.Claims, error) {
+	return s.claims, s.err
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		header     string
+		tokens     *stubTokens
+		wantStatus int
+		wantCode   int
+	}{
+		{"缺少认证头", "", &stubTokens{}, http.StatusUnauthorized, apperr.MissingToken.Code},
+		{"格式错误", "Basic abc", &stubTokens{}, http.StatusUnauthorized, apperr.MalformedToken.Code},
+		{"token 无效", "Bearer t", &stubTokens{err: service.ErrInvalidToken}, http.StatusUnauthorized, apperr.InvalidToken.Code},
+		{"查询会话失败", "Bearer t", &stubTokens{err: errors.New("database is locked")}, http.StatusInternalServerError, apperr.Internal.Code},
+		{"查询超时", "Bearer t", &stubTokens{err: context.DeadlineExceeded}, http.StatusGatewayTimeout, apperr.RequestTimeout.Code},
+		{"请求取消", "Bearer t", &stubTokens{err: context.Canceled}, http.StatusServiceUnavailable, apperr.RequestCanceled.Code},
+		{"必须修改密码", "Bearer t", &stubTokens{claims: &service.Claims{UserID: 1, PasswordChangeRequired: true}}, http.StatusForbidden, apperr.PasswordChangeRequired.Code},
+		{"通过", "Bearer t", &stubTokens{claims: &service.Claims{UserID: 1}}, http.StatusOK, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(ErrorMiddleware())
+			r.GET("/api/users", AuthMiddleware(tt.tokens), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"code": 0})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// internal/middleware/error.go - 统一错误渲染
+//
+// 📌 handler 与其他中间件只调用 c.Error(err)，本中间件在请求结束后统一输出:
+//
+//	{"code": 40001, "message": "参数错误", "details": [{"field": "email", "rule": "email", "message": "..."}], "request_id": "..."}
+//
+// 📌 错误经 apperr.From 转换，未注册的错误一律返回 50001，原始错误只写入日志
+// 📌 文案语言按 Accept-Language 选择
+package middleware
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"user-management/internal/apperr"
+	"user-management/internal/logging"
+	"user-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// errorResponse 错误响应体
+type errorResponse struct {
+	Code      int                 `json:"code"`
+	Message   string              `json:"message"`
+	Details   []apperr.FieldError `json:"details,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// ErrorMiddleware 统一错误渲染中间件，需注册在其他业务中间件之前
+// 📌 已写出响应体时不再处理（如导出文件中途失败）
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		appErr := apperr.From(err)
+		if appErr.Status >= 500 {
+			logging.FromContext(c.Request.Context()).Error("请求处理失败",
+				zap.Error(err),
+				zap.Int("code", appErr.Code),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)
+		}
+		renderError(c, err)
+	}
+}
+
+// renderError 输出错误响应并终止后续处理
+func renderError(c *gin.Context, err error) {
+	// 锁定类错误附带 Retry-After 头（秒）
+	var lockoutErr *service.LockoutError
+	if errors.As(err, &lockoutErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockoutErr.RetryAfter.Seconds()))))
+	}
+
+	appErr := apperr.From(err).Localize(apperr.MatchLanguage(c.GetHeader("Accept-Language")))
+	c.AbortWithStatusJSON(appErr.Status, errorResponse{
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		Details:   appErr.Details,
+		RequestID: c.GetString("requestID"),
+	})
+}
+
+// NotFound 未匹配到路由
+func NotFound(c *gin.Context) {
+	c.Error(apperr.RouteNotFound)
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/password"
+	"user-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		err            error
+		lang           string
+		wantStatus     int
+		wantCode       int
+		wantMessage    string
+		wantRetryAfter string
+		wantDetails    int
+	}{
+		{"注册的错误", apperr.UserNotFound, "", http.StatusNotFound, apperr.UserNotFound.Code, "用户不存在", "", 0},
+		{"包装后的注册错误", fmt.Errorf("查询失败: %w", apperr.UserNotFound), "", http.StatusNotFound, apperr.UserNotFound.Code, "用户不存在", "", 0},
+		{"英文文案", apperr.UserNotFound, "en-US,en;q=0.9", http.StatusNotFound, apperr.UserNotFound.Code, "User not found", "", 0},
+		{"不支持的语言回退中文", apperr.UserNotFound, "fr", http.StatusNotFound, apperr.UserNotFound.Code, "用户不存在", "", 0},
+		{"未注册的错误不泄露原文", errors.New("dial tcp 10.0.0.5:5432: connection refused"), "", http.StatusInternalServerError, apperr.Internal.Code, "服务器错误", "", 0},
+		{"SQL 超时", fmt.Errorf("query: %w", context.DeadlineExceeded), "", http.StatusGatewayTimeout, apperr.RequestTimeout.Code, apperr.RequestTimeout.Message, "", 0},
+		{"请求取消", context.Canceled, "", http.StatusServiceUnavailable, apperr.RequestCanceled.Code, apperr.RequestCanceled.Message, "", 0},
+		{"用户名锁定", &service.LockoutError{Err: service.ErrLoginTemporarilyLocked, RetryAfter: 90*time.Second + 200*time.Millisecond}, "", http.StatusLocked, apperr.LoginTemporarilyLocked.Code, apperr.LoginTemporarilyLocked.Message, "91", 0},
+		{"IP 限流", &service.LockoutError{Err: service.ErrTooManyLoginAttempts, RetryAfter: time.Minute}, "", http.StatusTooManyRequests, apperr.TooManyLoginAttempts.Code, apperr.TooManyLoginAttempts.Message, "60", 0},
+		{"字段详情", &password.PolicyError{Violations: []password.Violation{{Rule: "min_length", Param: "8"}, {Rule: "digit"}}}, "", http.StatusBadRequest, apperr.PasswordPolicy.Code, apperr.PasswordPolicy.Message, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(ErrorMiddleware())
+			r.GET("/test", func(c *gin.Context) {
+				c.Set("requestID", "req-1")
+				c.Error(tt.err)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			var body errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode || body.Message != tt.wantMessage {
+				t.Errorf("body = %+v, want code %d message %q", body, tt.wantCode, tt.wantMessage)
+			}
+			if len(body.Details) != tt.wantDetails {
+				t.Errorf("details = %+v, want %d", body.Details, tt.wantDetails)
+			}
+			if body.RequestID != "req-1" {
+				t.Errorf("request_id = %q, want req-1", body.RequestID)
+			}
+		})
+	}
+}
+
+func TestErrorMiddlewareSkipsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.NoRoute(NotFound)
+	r.GET("/export", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		c.Error(errors.New("write failed"))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("written response changed: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusNotFound || body.Code != apperr.RouteNotFound.Code {
+		t.Errorf("no route: status = %d, code = %d", rec.Code, body.Code)
+	}
+}
@@ -1,50 +1,125 @@
 // internal/middleware/logger.go - 日志中间件
+//
+// 📌 日志统一通过 logging.FromContext 获取，自动带上 RequestIDMiddleware 写入的 request_id
+// 📌 查询串、请求头与 body 经 redact.Redactor 脱敏后才写入日志
 package middleware
 
 import (
+	"bytes"
+	"io"
+	"net/http"
 	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/logging"
+	"user-management/internal/redact"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 // LoggerMiddleware Zap 日志中间件
-func LoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
+// 📌 查询串始终经过脱敏；logBody 为 true 时额外记录请求头、请求/响应 body（同样脱敏）
+func LoggerMiddleware(redactor *redact.Redactor, logBody bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
 
+		var reqBody []byte
+		var reqComplete bool
+		var respWriter *bodyLogWriter
+		if logBody {
+			reqBody, reqComplete = peekRequestBody(c.Request)
+			respWriter = &bodyLogWriter{ResponseWriter: c.Writer}
+			c.Writer = respWriter
+		}
+
 		c.Next()
 
 		latency := time.Since(start)
 		status := c.Writer.Status()
 
-		logger.Info("HTTP请求",
+		fields := []zap.Field{
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
-			zap.String("query", query),
+			zap.String("query", redactor.Query(query)),
 			zap.Int("status", status),
 			zap.Duration("latency", latency),
 			zap.String("ip", c.ClientIP()),
 			zap.String("user-agent", c.Request.UserAgent()),
-		)
+		}
+		if logBody {
+			fields = append(fields,
+				zap.Any("headers", redactor.Headers(c.Request.Header)),
+				zap.String("request_body", redactor.Body(path, c.GetHeader("Content-Type"), reqBody, reqComplete)),
+				zap.String("response_body", redactor.Body(path, c.Writer.Header().Get("Content-Type"), respWriter.body.Bytes(), respWriter.complete())),
+			)
+		}
+		logging.FromContext(c.Request.Context()).Info("HTTP请求", fields...)
+	}
+}
+
+// bodyCaptureLimit body 采集上限，超出部分不缓存（JSON 超出上限时无法脱敏，整体不记录）
+const bodyCaptureLimit = 64 << 10
+
+// peekRequestBody 读取请求 body 的前 bodyCaptureLimit 字节，并把读取的部分放回 body
+func peekRequestBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	buf, _ := io.ReadAll(io.LimitReader(req.Body, bodyCaptureLimit+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+
+	if len(buf) > bodyCaptureLimit {
+		return buf[:bodyCaptureLimit], false
+	}
+	return buf, true
+}
+
+// bodyLogWriter 在写出响应的同时缓存前 bodyCaptureLimit 字节
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	total int
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(b []byte) {
+	w.total += len(b)
+	if remaining := bodyCaptureLimit - w.body.Len(); remaining > 0 {
+		if len(b) > remaining {
+			b = b[:remaining]
+		}
+		w.body.Write(b)
 	}
 }
 
+func (w *bodyLogWriter) complete() bool {
+	return w.total <= bodyCaptureLimit
+}
+
 // RecoveryMiddleware 恢复中间件
-func RecoveryMiddleware(logger *zap.Logger) gin.HandlerFunc {
+func RecoveryMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("Panic recovered",
+				logging.FromContext(c.Request.Context()).Error("Panic recovered",
 					zap.Any("error", err),
 					zap.String("path", c.Request.URL.Path),
 				)
-				c.AbortWithStatusJSON(500, gin.H{
-					"code":    500,
-					"message": "服务器内部错误",
-				})
+				renderError(c, apperr.Internal)
 			}
 		}()
 		c.Next()
@@ -0,0 +1,57 @@
+// internal/middleware/permission.go - 权限中间件
+//
+// 📌 权限来源:
+//   - claims: 直接读取 token 中的 permissions 声明，无需查库，变更在 token 刷新后生效
+//   - db: 每次请求实时查询数据库，变更立即生效
+package middleware
+
+import (
+	"context"
+	"user-management/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionResolver 权限查询接口
+type PermissionResolver interface {
+	GetUserPermissions(ctx context.Context, userID uint) ([]string, error)
+}
+
+// RequirePermission 细粒度权限中间件，需在 AuthMiddleware 之后使用
+// 📌 resolver 为 nil 时使用 token 中的权限声明
+func RequirePermission(resolver PermissionResolver, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var permissions []string
+		if resolver == nil {
+			permissions = c.GetStringSlice("permissions")
+		} else {
+			perms, err := resolver.GetUserPermissions(c.Request.Context(), c.GetUint("userID"))
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			permissions = perms
+		}
+
+		if !hasPermission(permissions, permission) {
+			c.Error(apperr.PermissionDenied.WithDetails(apperr.FieldError{
+				Field: "permission",
+				Rule:  "permission",
+				Param: permission,
+			}))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasPermission(permissions []string, target string) bool {
+	for _, p := range permissions {
+		if p == target {
+			return true
+		}
+	}
+	return false
+}
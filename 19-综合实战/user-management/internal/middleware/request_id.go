@@ -0,0 +1,49 @@
+// internal/middleware/request_id.go - 请求 ID 中间件
+//
+// 📌 优先沿用上游（网关/调用方）传入的 X-Request-ID，格式不合法或缺失时生成新的 ID
+// 📌 请求 ID 写入响应头、错误响应体、审计日志，以及请求级 logger 的 request_id 字段
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"user-management/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HeaderRequestID 请求 ID 头
+const HeaderRequestID = "X-Request-ID"
+
+// 📌 长度与 audit_events.request_id 列一致，只允许安全字符，防止日志注入
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestIDMiddleware 请求 ID 中间件，需注册在最前面
+func RequestIDMiddleware(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = generateRequestID()
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		ctx = logging.WithLogger(ctx, logger.With(zap.String("request_id", requestID)))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// generateRequestID 生成 32 位十六进制随机 ID
+func generateRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
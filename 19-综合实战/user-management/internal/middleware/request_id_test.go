@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"user-management/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var generatedIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"沿用上游 ID", "gw-7f3a.9:1", true},
+		{"UUID", "3f2b8c1e-4d5a-4b6c-8e7f-0a1b2c3d4e5f", true},
+		{"64 位", strings.Repeat("a", 64), true},
+		{"缺失", "", false},
+		{"超过 64 位", strings.Repeat("a", 65), false},
+		{"包含空格", "abc def", false},
+		{"日志注入", "abc\r\nlevel=error", false},
+		{"JSON 注入", `abc","admin":true`, false},
+		{"非 ASCII", "请求-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			var ctxID string
+			r := gin.New()
+			r.Use(RequestIDMiddleware(zap.New(core)))
+			r.GET("/test", func(c *gin.Context) {
+				ctxID = logging.RequestID(c.Request.Context())
+				logging.FromContext(c.Request.Context()).Info("handled")
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestID, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(HeaderRequestID)
+			if tt.keep && got != tt.header {
+				t.Errorf("request ID = %q, want %q", got, tt.header)
+			}
+			if !tt.keep && !generatedIDPattern.MatchString(got) {
+				t.Errorf("request ID = %q, want generated 32 hex chars", got)
+			}
+			if ctxID != got {
+				t.Errorf("context request ID = %q, want %q", ctxID, got)
+			}
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].ContextMap()["request_id"] != got {
+				t.Errorf("log entries = %+v, want request_id %q", entries, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// internal/migrate/legacy.go - 兼容 AutoMigrate 建立的旧数据库
+//
+// 📌 引入版本化迁移之前，users 表由 AutoMigrate 按当时的模型创建（基线版本只有
+// id/username/email/password/role/created_at/updated_at），之后新增的列不会出现在旧库中
+// 📌 SQLite 的 0001 使用 CREATE TABLE IF NOT EXISTS 跳过已存在的表，
+// 因此执行 0001 前先用 ALTER TABLE 补齐缺少的列，否则后续建索引会失败
+// 📌 旧库的 users.role 列在改为 RBAC 后不再使用，0001 之后把它复制到 user_roles，否则升级后所有用户（包括管理员）都会丢失角色
+// 📌 基线版本启动时会创建 admin/admin123，0002 之后旧库的管理员一律要求修改密码，仍在使用该默认密码的直接禁用
+// 📌 PostgreSQL / MySQL 没有 AutoMigrate 建表的发布版本，0001 要求空库
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"user-management/internal/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// baselineAdminPassword 基线版本内置管理员的默认密码
+const baselineAdminPassword = "admin123"
+
+// legacyColumn 基线之后 users 表新增的列
+type legacyColumn struct {
+	Name       string
+	Definition string
+}
+
+// legacyUserColumns 按方言列出需要补齐的列，定义与 0001 保持一致
+// 📌 SQLite 的 ADD COLUMN 不允许 NOT NULL 且无默认值的列，status 带默认值可以直接添加
+var legacyUserColumns = map[string][]legacyColumn{
+	"sqlite": {
+		{Name: "status", Definition: "text NOT NULL DEFAULT 'active'"},
+		{Name: "email_verified_at", Definition: "datetime"},
+		{Name: "deleted_at", Definition: "datetime"},
+	},
+}
+
+// legacyRoleSQL 把 users.role 复制为 user_roles 关联，按方言区分
+// 📌 内置角色的描述与 RBACService.SeedDefaults 一致；其他旧角色名原样建为角色
+// 📌 role 为空的用户视为普通用户（旧模型的默认值为 user）
+var legacyRoleSQL = map[string]string{
+	"sqlite": `
+INSERT INTO roles (name, description, created_at, updated_at)
+SELECT 'user', '普通用户', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE name = 'user');
+INSERT INTO roles (name, description, created_at, updated_at)
+SELECT 'admin', '管理员', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE name = 'admin');
+INSERT INTO roles (name, description, created_at, updated_at)
+SELECT DISTINCT u.role, '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM users u
+WHERE u.role IS NOT NULL AND u.role <> '' AND NOT EXISTS (SELECT 1 FROM roles r WHERE r.name = u.role);
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u JOIN roles r ON r.name = COALESCE(NULLIF(u.role, ''), 'user')
+WHERE NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id);
+`,
+}
+
+// legacyPrelude 检测到旧库时返回 0001 之前需要执行的语句，新库返回空串
+func (m *Migrator) legacyPrelude(ctx context.Context) (string, error) {
+	columns, ok := legacyUserColumns[m.db.Dialector.Name()]
+	if !ok {
+		return "", nil
+	}
+	migrator := m.db.WithContext(ctx).Migrator()
+	if !migrator.HasTable("users") {
+		return "", nil
+	}
+
+	var stmts []string
+	for _, column := range columns {
+		if migrator.HasColumn("users", column.Name) {
+			continue
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE `users` ADD COLUMN `%s` %s;\n", column.Name, column.Definition))
+	}
+	return strings.Join(stmts, ""), nil
+}
+
+// legacyPostlude 旧库存在 users.role 列时返回 0001 之后需要执行的数据迁移，否则返回空串
+func (m *Migrator) legacyPostlude(ctx context.Context) (string, error) {
+	script, ok := legacyRoleSQL[m.db.Dialector.Name()]
+	if !ok {
+		return "", nil
+	}
+	migrator := m.db.WithContext(ctx).Migrator()
+	if !migrator.HasTable("users") || !migrator.HasColumn("users", "role") {
+		return "", nil
+	}
+	return "\n" + script, nil
+}
+
+// legacyAdminHook 旧库返回在 0002 同一事务中执行的管理员加固，否则返回 nil
+// 📌 must_change_password 列由 0002 添加，因此不能放在 0001 的 postlude 中
+func (m *Migrator) legacyAdminHook(ctx context.Context) func(tx *gorm.DB) error {
+	if _, ok := legacyRoleSQL[m.db.Dialector.Name()]; !ok {
+		return nil
+	}
+	migrator := m.db.WithContext(ctx).Migrator()
+	if !migrator.HasTable("users") || !migrator.HasColumn("users", "role") {
+		return nil
+	}
+	return secureLegacyAdmins
+}
+
+// secureLegacyAdmins 旧库管理员首次登录必须修改密码；仍是默认密码的账号禁用
+// 📌 只改 must_change_password 不够：知道默认密码的人可以先登录再改密码，账号就归他了
+// 📌 被禁用的管理员由其他管理员启用，或用 server admin create 创建新管理员
+func secureLegacyAdmins(tx *gorm.DB) error {
+	var admins []struct {
+		ID       uint
+		Password string
+	}
+	err := tx.Table("users").Select("users.id, users.password").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ?", model.RoleAdmin).
+		Scan(&admins).Error
+	if err != nil {
+		return err
+	}
+
+	for _, admin := range admins {
+		updates := map[string]interface{}{"must_change_password": true}
+		if bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(baselineAdminPassword)) == nil {
+			updates["status"] = model.UserStatusDisabled
+		}
+		if err := tx.Table("users").Where("id = ?", admin.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
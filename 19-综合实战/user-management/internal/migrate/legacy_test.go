@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/database"
+	"user-management/internal/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// baselineUser 引入版本化迁移之前由 AutoMigrate 建表的用户模型
+type baselineUser struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"uniqueIndex;size:50;not null"`
+	Email     string `gorm:"uniqueIndex;size:100;not null"`
+	Password  string `gorm:"size:100;not null"`
+	Role      string `gorm:"size:20;default:user"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineUser) TableName() string {
+	return "users"
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&config.DatabaseConfig{Driver: database.DriverSQLite, LogLevel: "silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func hash(t *testing.T, password string) string {
+	t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hashed)
+}
+
+func TestUpLegacyDatabase(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	if err := db.AutoMigrate(&baselineUser{}); err != nil {
+		t.Fatal(err)
+	}
+	legacy := []baselineUser{
+		{Username: "admin", Email: "admin@example.com", Password: hash(t, baselineAdminPassword), Role: model.RoleAdmin},
+		{Username: "ops", Email: "ops@example.com", Password: hash(t, "S3cure!pass"), Role: model.RoleAdmin},
+		{Username: "tom", Email: "tom@example.com", Password: hash(t, "tom-pass"), Role: model.RoleUser},
+		{Username: "jerry", Email: "jerry@example.com", Password: hash(t, "jerry-pass"), Role: ""},
+		{Username: "auditor", Email: "auditor@example.com", Password: hash(t, "auditor-pass"), Role: "auditor"},
+	}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		t.Fatalf("Check after Up: %v", err)
+	}
+
+	tests := []struct {
+		username           string
+		roles              string
+		status             string
+		mustChangePassword bool
+	}{
+		{"admin", "admin", model.UserStatusDisabled, true},
+		{"ops", "admin", model.UserStatusActive, true},
+		{"tom", "user", model.UserStatusActive, false},
+		{"jerry", "user", model.UserStatusActive, false},
+		{"auditor", "auditor", model.UserStatusActive, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			var user model.User
+			if err := db.Preload("Roles").Where("username = ?", tt.username).First(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+			roles := user.RoleNames()
+			sort.Strings(roles)
+			if got := strings.Join(roles, ","); got != tt.roles {
+				t.Errorf("roles = %q, want %q", got, tt.roles)
+			}
+			if user.Status != tt.status {
+				t.Errorf("status = %q, want %q", user.Status, tt.status)
+			}
+			if user.MustChangePassword != tt.mustChangePassword {
+				t.Errorf("must_change_password = %v, want %v", user.MustChangePassword, tt.mustChangePassword)
+			}
+		})
+	}
+}
+
+func TestUpFreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(ctx); err == nil {
+		t.Fatal("Check on empty database: want *SchemaBehindError")
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Fatalf("applied %d migrations, want %d", len(applied), len(migrator.migrations))
+	}
+	// 再次执行不会重复迁移
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second Up = (%d, %v), want (0, nil)", len(applied), err)
+	}
+}
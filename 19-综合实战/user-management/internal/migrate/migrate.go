@@ -0,0 +1,381 @@
+// internal/migrate/migrate.go - 版本化数据库迁移
+//
+// 📌 迁移文件通过 embed.FS 编译进二进制，按数据库方言分目录存放:
+//
+//	sql/sqlite/0001_init.up.sql
+//	sql/sqlite/0001_init.down.sql
+//	sql/postgres/...
+//	sql/mysql/...
+//
+// 📌 已执行的版本记录在 schema_migrations 表中
+// 📌 每个迁移在独立事务中执行（MySQL 的 DDL 会隐式提交，失败时需手动处理）
+// 📌 语句以分号 + 换行分隔，-- 开头的行视为注释
+// 📌 早期版本（SQLite）由 AutoMigrate 建表，执行 0001 前先补齐 users 表缺少的列，之后把 users.role 复制到 user_roles，
+// 0002 之后加固旧管理员账号，见 legacy.go
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var embedded embed.FS
+
+// DefaultDir migrate create 生成文件的默认目录（相对项目根目录）
+const DefaultDir = "internal/migrate/sql"
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// SchemaBehindError 数据库结构落后于当前代码
+type SchemaBehindError struct {
+	Pending []Migration
+}
+
+func (e *SchemaBehindError) Error() string {
+	return fmt.Sprintf("数据库有 %d 个待执行的迁移（最新版本 %04d），请先执行 migrate up",
+		len(e.Pending), e.Pending[len(e.Pending)-1].Version)
+}
+
+// schemaMigration schema_migrations 表
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 按数据库方言加载内置的迁移文件
+func New(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	fsys, err := fs.Sub(embedded, "sql/"+dialect)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("加载 %s 迁移文件失败: %w", dialect, err)
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("没有 %s 的迁移文件", dialect)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up 按版本顺序执行全部待执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	// 📌 只有 up 会建 schema_migrations 表，status/check 等只读操作不修改数据库结构
+	if err := m.db.WithContext(ctx).AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		script := migration.UpSQL
+		if migration.Version == 1 {
+			prelude, err := m.legacyPrelude(ctx)
+			if err != nil {
+				return nil, err
+			}
+			postlude, err := m.legacyPostlude(ctx)
+			if err != nil {
+				return nil, err
+			}
+			script = prelude + script + postlude
+		}
+		var hook func(tx *gorm.DB) error
+		if migration.Version == 2 {
+			hook = m.legacyAdminHook(ctx)
+		}
+		err := m.run(ctx, script, func(tx *gorm.DB) error {
+			if hook != nil {
+				if err := hook(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("执行迁移 %04d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// Down 回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按版本倒序回滚
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var rolledBack []Migration
+	for _, version := range versions {
+		migration, ok := m.find(version)
+		if !ok {
+			return rolledBack, fmt.Errorf("找不到版本 %04d 的迁移文件，无法回滚", version)
+		}
+		err := m.run(ctx, migration.DownSQL, func(tx *gorm.DB) error {
+			return tx.Delete(&schemaMigration{}, version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("回滚迁移 %04d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+	return rolledBack, nil
+}
+
+// Status 返回所有迁移及其执行时间
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回尚未执行的迁移
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Check 数据库结构落后时返回 *SchemaBehindError
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return &SchemaBehindError{Pending: pending}
+	}
+	return nil
+}
+
+// applied 返回已执行的版本及执行时间，schema_migrations 表不存在时视为没有执行过任何迁移
+// 📌 只读：就绪检查每次都会调用，不能在这里建表
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int64]time.Time{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+// run 在同一事务中执行迁移语句并更新版本记录
+func (m *Migrator) run(ctx context.Context, script string, record func(tx *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return record(tx)
+	})
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Create 在 dir 下每个方言目录中生成下一个版本的 up/down 空文件，返回生成的文件路径
+func Create(dir, name string) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, errors.New("迁移名称只能包含小写字母、数字和下划线")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dialects []string
+	var next int64 = 1
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dialects = append(dialects, entry.Name())
+
+		migrations, err := load(os.DirFS(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version >= next {
+			next = migrations[n-1].Version + 1
+		}
+	}
+	if len(dialects) == 0 {
+		return nil, fmt.Errorf("%s 下没有方言目录", dir)
+	}
+
+	var paths []string
+	for _, dialect := range dialects {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, dialect, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			content := fmt.Sprintf("-- %04d_%s (%s, %s)\n", next, name, dialect, direction)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// load 读取目录中的迁移文件，每个版本必须同时有 up 和 down 文件
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("版本 %04d 存在多个名称: %s, %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" || migration.DownSQL == "" {
+			return nil, fmt.Errorf("迁移 %04d_%s 缺少 up 或 down 文件", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements 去掉注释行并按分号拆分语句
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var stmts []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";\n") {
+		stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+		if strings.TrimSpace(stmt) != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
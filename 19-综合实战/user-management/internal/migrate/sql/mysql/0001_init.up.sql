@@ -0,0 +1,137 @@
+-- 初始表结构
+-- 📌 要求空库: MySQL 没有由 AutoMigrate 建表的发布版本
+-- 📌 MySQL 不支持部分索引，用户名/邮箱的唯一索引建在生成列上: 软删除后生成列为 NULL，
+--    多个 NULL 不冲突，效果等同于 PostgreSQL/SQLite 的 WHERE deleted_at IS NULL
+
+CREATE TABLE `users` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `username` varchar(50) NOT NULL,
+    `email` varchar(100) NOT NULL,
+    `password` varchar(100) NOT NULL,
+    `status` varchar(20) NOT NULL DEFAULT 'active',
+    `email_verified_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `username_active` varchar(50) GENERATED ALWAYS AS (IF(`deleted_at` IS NULL, `username`, NULL)) STORED,
+    `email_active` varchar(100) GENERATED ALWAYS AS (IF(`deleted_at` IS NULL, `email`, NULL)) STORED,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_users_username_active` (`username_active`),
+    UNIQUE INDEX `idx_users_email_active` (`email_active`),
+    INDEX `idx_users_status` (`status`),
+    INDEX `idx_users_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `roles` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(50) NOT NULL,
+    `description` varchar(200),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_roles_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `permissions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `code` varchar(100) NOT NULL,
+    `description` varchar(200),
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_permissions_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `user_roles` (
+    `user_id` bigint unsigned,
+    `role_id` bigint unsigned,
+    PRIMARY KEY (`user_id`, `role_id`),
+    CONSTRAINT `fk_user_roles_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `role_permissions` (
+    `role_id` bigint unsigned,
+    `permission_id` bigint unsigned,
+    PRIMARY KEY (`role_id`, `permission_id`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `sessions` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `refresh_token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_sessions_refresh_token_hash` (`refresh_token_hash`),
+    INDEX `idx_sessions_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `login_attempts` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `attempt_key` varchar(150) NOT NULL,
+    `failures` bigint NOT NULL DEFAULT 0,
+    `last_failed_at` datetime(3) NOT NULL,
+    `locked_until` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_login_attempts_key` (`attempt_key`),
+    INDEX `idx_login_attempts_locked_until` (`locked_until`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `user_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `purpose` varchar(30) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_user_tokens_token_hash` (`token_hash`),
+    INDEX `idx_user_tokens_user_id` (`user_id`),
+    INDEX `idx_user_tokens_purpose` (`purpose`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `user_mfa` (
+    `user_id` bigint unsigned,
+    `secret` varchar(64) NOT NULL,
+    `enabled_at` datetime(3) NULL,
+    `last_step` bigint,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `mfa_recovery_codes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `used_at` datetime(3) NULL,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_mfa_recovery_codes_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `audit_events` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `actor_id` bigint unsigned,
+    `actor_name` varchar(50),
+    `action` varchar(50) NOT NULL,
+    `target_id` bigint unsigned,
+    `target_name` varchar(50),
+    `ip` varchar(45),
+    `user_agent` varchar(255),
+    `request_id` varchar(64),
+    `changes` text,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_events_actor_id` (`actor_id`),
+    INDEX `idx_audit_events_action` (`action`),
+    INDEX `idx_audit_events_target_id` (`target_id`),
+    INDEX `idx_audit_events_request_id` (`request_id`),
+    INDEX `idx_audit_events_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+-- 已轮换的 refresh token，用于检测重复使用
+CREATE TABLE `rotated_refresh_tokens` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `session_id` bigint unsigned NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `rotated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_rotated_refresh_tokens_token_hash` (`token_hash`),
+    INDEX `idx_rotated_refresh_tokens_session_id` (`session_id`),
+    INDEX `idx_rotated_refresh_tokens_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,125 @@
+-- 初始表结构
+-- 📌 要求空库: PostgreSQL 没有由 AutoMigrate 建表的发布版本
+
+CREATE TABLE "users" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar(50) NOT NULL,
+    "email" varchar(100) NOT NULL,
+    "password" varchar(100) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'active',
+    "email_verified_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz
+);
+-- 用户名/邮箱只约束未删除的行，软删除后可重新注册
+CREATE UNIQUE INDEX "idx_users_username_active" ON "users" ("username") WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX "idx_users_email_active" ON "users" ("email") WHERE deleted_at IS NULL;
+CREATE INDEX "idx_users_status" ON "users" ("status");
+CREATE INDEX "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE "roles" (
+    "id" bigserial PRIMARY KEY,
+    "name" varchar(50) NOT NULL,
+    "description" varchar(200),
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_roles_name" ON "roles" ("name");
+
+CREATE TABLE "permissions" (
+    "id" bigserial PRIMARY KEY,
+    "code" varchar(100) NOT NULL,
+    "description" varchar(200),
+    "created_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_permissions_code" ON "permissions" ("code");
+
+CREATE TABLE "user_roles" (
+    "user_id" bigint,
+    "role_id" bigint,
+    PRIMARY KEY ("user_id", "role_id"),
+    CONSTRAINT "fk_user_roles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_user_roles_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
+
+CREATE TABLE "role_permissions" (
+    "role_id" bigint,
+    "permission_id" bigint,
+    PRIMARY KEY ("role_id", "permission_id"),
+    CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id"),
+    CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions"("id")
+);
+
+CREATE TABLE "sessions" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL,
+    "refresh_token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_sessions_refresh_token_hash" ON "sessions" ("refresh_token_hash");
+CREATE INDEX "idx_sessions_user_id" ON "sessions" ("user_id");
+
+CREATE TABLE "login_attempts" (
+    "id" bigserial PRIMARY KEY,
+    "attempt_key" varchar(150) NOT NULL,
+    "failures" bigint NOT NULL DEFAULT 0,
+    "last_failed_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_login_attempts_key" ON "login_attempts" ("attempt_key");
+CREATE INDEX "idx_login_attempts_locked_until" ON "login_attempts" ("locked_until");
+
+CREATE TABLE "user_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL,
+    "purpose" varchar(30) NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_user_tokens_token_hash" ON "user_tokens" ("token_hash");
+CREATE INDEX "idx_user_tokens_user_id" ON "user_tokens" ("user_id");
+CREATE INDEX "idx_user_tokens_purpose" ON "user_tokens" ("purpose");
+
+CREATE TABLE "user_mfa" (
+    "user_id" bigint PRIMARY KEY,
+    "secret" varchar(64) NOT NULL,
+    "enabled_at" timestamptz,
+    "last_step" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+
+CREATE TABLE "mfa_recovery_codes" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz
+);
+CREATE INDEX "idx_mfa_recovery_codes_user_id" ON "mfa_recovery_codes" ("user_id");
+
+CREATE TABLE "audit_events" (
+    "id" bigserial PRIMARY KEY,
+    "actor_id" bigint,
+    "actor_name" varchar(50),
+    "action" varchar(50) NOT NULL,
+    "target_id" bigint,
+    "target_name" varchar(50),
+    "ip" varchar(45),
+    "user_agent" varchar(255),
+    "request_id" varchar(64),
+    "changes" text,
+    "created_at" timestamptz
+);
+CREATE INDEX "idx_audit_events_actor_id" ON "audit_events" ("actor_id");
+CREATE INDEX "idx_audit_events_action" ON "audit_events" ("action");
+CREATE INDEX "idx_audit_events_target_id" ON "audit_events" ("target_id");
+CREATE INDEX "idx_audit_events_request_id" ON "audit_events" ("request_id");
+CREATE INDEX "idx_audit_events_created_at" ON "audit_events" ("created_at");
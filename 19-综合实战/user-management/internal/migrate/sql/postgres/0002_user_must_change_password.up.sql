@@ -0,0 +1,2 @@
+-- 初始管理员首次登录必须修改密码
+ALTER TABLE "users" ADD COLUMN "must_change_password" boolean NOT NULL DEFAULT false;
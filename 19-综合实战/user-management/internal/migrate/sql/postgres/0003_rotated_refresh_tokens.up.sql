@@ -0,0 +1,11 @@
+-- 已轮换的 refresh token，用于检测重复使用
+CREATE TABLE "rotated_refresh_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "session_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "rotated_at" timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX "idx_rotated_refresh_tokens_token_hash" ON "rotated_refresh_tokens" ("token_hash");
+CREATE INDEX "idx_rotated_refresh_tokens_session_id" ON "rotated_refresh_tokens" ("session_id");
+CREATE INDEX "idx_rotated_refresh_tokens_user_id" ON "rotated_refresh_tokens" ("user_id");
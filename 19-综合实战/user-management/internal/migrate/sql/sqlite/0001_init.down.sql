@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `audit_events`;
+DROP TABLE IF EXISTS `mfa_recovery_codes`;
+DROP TABLE IF EXISTS `user_mfa`;
+DROP TABLE IF EXISTS `user_tokens`;
+DROP TABLE IF EXISTS `login_attempts`;
+DROP TABLE IF EXISTS `sessions`;
+DROP TABLE IF EXISTS `role_permissions`;
+DROP TABLE IF EXISTS `user_roles`;
+DROP TABLE IF EXISTS `permissions`;
+DROP TABLE IF EXISTS `roles`;
+DROP TABLE IF EXISTS `users`;
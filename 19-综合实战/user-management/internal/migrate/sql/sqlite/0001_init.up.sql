@@ -0,0 +1,129 @@
+-- 初始表结构
+-- 📌 使用 IF NOT EXISTS，兼容之前由 AutoMigrate 创建的数据库
+-- 📌 旧库 users 表缺少的列（status/email_verified_at/deleted_at）由 Migrator 在本脚本之前补齐，见 legacy.go
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `username` text NOT NULL,
+    `email` text NOT NULL,
+    `password` text NOT NULL,
+    `status` text NOT NULL DEFAULT 'active',
+    `email_verified_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime
+);
+-- 旧版本用户名/邮箱为全表唯一索引，改为只约束未删除的行
+DROP INDEX IF EXISTS `idx_users_username`;
+DROP INDEX IF EXISTS `idx_users_email`;
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_username_active` ON `users`(`username`) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email_active` ON `users`(`email`) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS `idx_users_status` ON `users`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `roles` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `description` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_roles_name` ON `roles`(`name`);
+
+CREATE TABLE IF NOT EXISTS `permissions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `code` text NOT NULL,
+    `description` text,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_permissions_code` ON `permissions`(`code`);
+
+CREATE TABLE IF NOT EXISTS `user_roles` (
+    `user_id` integer,
+    `role_id` integer,
+    PRIMARY KEY (`user_id`, `role_id`),
+    CONSTRAINT `fk_user_roles_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+    `role_id` integer,
+    `permission_id` integer,
+    PRIMARY KEY (`role_id`, `permission_id`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `sessions` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `refresh_token_hash` text NOT NULL,
+    `expires_at` datetime,
+    `revoked_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_sessions_refresh_token_hash` ON `sessions`(`refresh_token_hash`);
+CREATE INDEX IF NOT EXISTS `idx_sessions_user_id` ON `sessions`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `login_attempts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `attempt_key` text NOT NULL,
+    `failures` integer NOT NULL DEFAULT 0,
+    `last_failed_at` datetime NOT NULL,
+    `locked_until` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_login_attempts_key` ON `login_attempts`(`attempt_key`);
+CREATE INDEX IF NOT EXISTS `idx_login_attempts_locked_until` ON `login_attempts`(`locked_until`);
+
+CREATE TABLE IF NOT EXISTS `user_tokens` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `purpose` text NOT NULL,
+    `token_hash` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_user_tokens_token_hash` ON `user_tokens`(`token_hash`);
+CREATE INDEX IF NOT EXISTS `idx_user_tokens_user_id` ON `user_tokens`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_user_tokens_purpose` ON `user_tokens`(`purpose`);
+
+CREATE TABLE IF NOT EXISTS `user_mfa` (
+    `user_id` integer,
+    `secret` text NOT NULL,
+    `enabled_at` datetime,
+    `last_step` integer,
+    `created_at` datetime,
+    `updated_at` datetime,
+    PRIMARY KEY (`user_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `mfa_recovery_codes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `code_hash` text NOT NULL,
+    `used_at` datetime,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_mfa_recovery_codes_user_id` ON `mfa_recovery_codes`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `audit_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `actor_id` integer,
+    `actor_name` text,
+    `action` text NOT NULL,
+    `target_id` integer,
+    `target_name` text,
+    `ip` text,
+    `user_agent` text,
+    `request_id` text,
+    `changes` text,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_actor_id` ON `audit_events`(`actor_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_action` ON `audit_events`(`action`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_target_id` ON `audit_events`(`target_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_request_id` ON `audit_events`(`request_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_events_created_at` ON `audit_events`(`created_at`);
@@ -0,0 +1,94 @@
+// internal/model/audit.go - 审计日志模型
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 审计动作
+const (
+	AuditUserRegister       = "user.register"
+	AuditUserLogin          = "user.login"
+	AuditUserLoginFailed    = "user.login_failed"
+	AuditUserProfileUpdate  = "user.profile_update"
+	AuditUserPasswordChange = "user.password_change"
+	AuditUserPasswordReset  = "user.password_reset"
+	AuditUserEmailVerify    = "user.email_verify"
+	AuditUserDelete         = "user.delete"
+	AuditUserRestore        = "user.restore"
+	AuditUserPurge          = "user.purge"
+	AuditUserDisable        = "user.disable"
+	AuditUserLock           = "user.lock"
+	AuditUserEnable         = "user.enable"
+	AuditUserUnlock         = "user.unlock"
+	AuditUserRolesAssign    = "user.roles_assign"
+	AuditUserMFAEnable      = "user.mfa_enable"
+	AuditUserMFADisable     = "user.mfa_disable"
+	AuditUserMFAReset       = "user.mfa_reset"
+	AuditRoleCreate         = "role.create"
+	AuditRoleUpdate         = "role.update"
+	AuditRoleDelete         = "role.delete"
+	AuditPermissionCreate   = "permission.create"
+)
+
+// Actor 操作者信息（谁、从哪里发起的请求），由 handler 从请求中提取
+// 📌 匿名请求（注册、登录、找回密码）UserID 为 0；后台任务传 nil
+type Actor struct {
+	UserID    uint
+	Username  string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// AuditEvent 审计事件
+// 📌 只追加不修改；Changes 为 JSON 格式的字段变更 {"字段": {"before": 旧值, "after": 新值}}
+type AuditEvent struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	ActorID    uint         `json:"actor_id" gorm:"index"` // 0 表示匿名或系统
+	ActorName  string       `json:"actor_name" gorm:"size:50"`
+	Action     string       `json:"action" gorm:"size:50;not null;index"`
+	TargetID   uint         `json:"target_id" gorm:"index"`     // 被操作对象的 ID，user.* 为用户，role.* 为角色，permission.* 为权限
+	TargetName string       `json:"target_name" gorm:"size:50"` // 用户名 / 角色名 / 权限码
+	IP         string       `json:"ip" gorm:"size:45"`
+	UserAgent  string       `json:"user_agent" gorm:"size:255"`
+	RequestID  string       `json:"request_id" gorm:"size:64;index"`
+	Changes    AuditChanges `json:"changes,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditChanges 字段变更（JSON 文本）
+// 📌 数据库中按字符串保存，输出 JSON 时原样嵌入而不是再转义成字符串
+type AuditChanges string
+
+// MarshalJSON 实现 json.Marshaler
+func (c AuditChanges) MarshalJSON() ([]byte, error) {
+	if c == "" {
+		return []byte("null"), nil
+	}
+	return json.RawMessage(c).MarshalJSON()
+}
+
+// ==================== DTO ====================
+
+// AuditQuery 审计日志查询条件
+// 📌 from / to 格式为 2006-01-02，to 包含当天
+// 📌 format=csv / json 时导出全部匹配记录（有上限），不分页
+type AuditQuery struct {
+	ActorID   uint       `form:"actor_id"`
+	TargetID  uint       `form:"target_id"`
+	Action    string     `form:"action" binding:"max=50"`
+	IP        string     `form:"ip" binding:"max=45"`
+	RequestID string     `form:"request_id" binding:"max=64"`
+	From      *time.Time `form:"from" time_format:"2006-01-02"`
+	To        *time.Time `form:"to" time_format:"2006-01-02"`
+	Page      int        `form:"page"`
+	PageSize  int        `form:"page_size"`
+	Format    string     `form:"format" binding:"omitempty,oneof=csv json"`
+}
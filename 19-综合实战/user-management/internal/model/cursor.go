@@ -0,0 +1,112 @@
+// internal/model/cursor.go - 游标分页
+//
+// 📌 游标分页（keyset pagination）:
+//   - 以 (created_at, id) 作为定位键，WHERE 条件直接命中索引，不需要 OFFSET 和 COUNT
+//   - 翻页期间有新用户注册也不会出现重复或遗漏
+//   - 游标对客户端是不透明的字符串，只需原样传回
+//
+// 📌 游标同时记录排序键、排序方向和筛选条件摘要，翻页时换了排序或筛选条件会被拒绝，
+//
+//	否则旧游标的位置在新的结果集里没有意义，会跳过或重复数据
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+	"user-management/internal/apperr"
+)
+
+var ErrInvalidCursor = apperr.InvalidCursor
+
+// UserCursor 用户列表游标
+type UserCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	SortBy    string    `json:"s"` // 排序键
+	SortOrder string    `json:"o"` // asc / desc
+	Filter    string    `json:"f"` // 筛选条件摘要，见 UserQuery.FilterHash
+}
+
+// Encode 编码为 URL 安全的不透明字符串
+func (c *UserCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor 解析客户端传回的游标
+func DecodeUserCursor(s string) (*UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 || cursor.SortBy == "" || cursor.SortOrder == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Match 检查游标是否由相同排序和筛选条件的查询生成，不一致返回 InvalidParams
+func (c *UserCursor) Match(query *UserQuery) error {
+	sortBy, sortOrder := query.cursorSort()
+	switch {
+	case c.SortBy != sortBy:
+		return cursorMismatch("sort_by")
+	case c.SortOrder != sortOrder:
+		return cursorMismatch("sort_order")
+	case c.Filter != query.FilterHash():
+		return cursorMismatch("filter")
+	}
+	return nil
+}
+
+func cursorMismatch(param string) error {
+	return apperr.InvalidParams.WithDetails(apperr.FieldError{Field: "cursor", Rule: "cursor_mismatch", Param: param})
+}
+
+// CursorOf 返回指向该用户的游标，记录本次查询的排序和筛选条件
+func (u *User) CursorOf(query *UserQuery) *UserCursor {
+	sortBy, sortOrder := query.cursorSort()
+	return &UserCursor{
+		CreatedAt: u.CreatedAt,
+		ID:        u.ID,
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		Filter:    query.FilterHash(),
+	}
+}
+
+// cursorSort 游标分页实际使用的排序键和方向（未指定时为 created_at desc）
+func (q *UserQuery) cursorSort() (string, string) {
+	sortBy, sortOrder := q.SortBy, q.SortOrder
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	if sortOrder != "asc" {
+		sortOrder = "desc"
+	}
+	return sortBy, sortOrder
+}
+
+// FilterHash 筛选条件摘要，分页参数（cursor/page/page_size）和排序不参与计算
+// 📌 只用于判断条件是否变化，截取前 16 字节即可
+func (q *UserQuery) FilterHash() string {
+	day := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	deleted := q.Deleted
+	if deleted == "" {
+		deleted = "exclude"
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		q.Keyword, q.Role, q.Status, day(q.CreatedFrom), day(q.CreatedTo), deleted,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,23 @@
+// internal/model/login_attempt.go - 登录失败记录
+package model
+
+import (
+	"time"
+)
+
+// LoginAttempt 登录失败计数
+// 📌 Key 形如 "user:tom" 或 "ip:10.0.0.1"，分别按用户名和客户端 IP 统计
+// 📌 找回密码、重发验证邮件的发信限流也记录在此表，Key 形如 "reset:tom@example.com"
+type LoginAttempt struct {
+	ID           uint       `gorm:"primaryKey"`
+	Key          string     `gorm:"column:attempt_key;uniqueIndex;size:150;not null"`
+	Failures     int        `gorm:"not null;default:0"`
+	LastFailedAt time.Time  `gorm:"not null"`
+	LockedUntil  *time.Time `gorm:"index"`
+	UpdatedAt    time.Time
+}
+
+// TableName 指定表名
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
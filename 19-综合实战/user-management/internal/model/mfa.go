@@ -0,0 +1,73 @@
+// internal/model/mfa.go - 两步验证模型
+package model
+
+import (
+	"time"
+)
+
+// UserMFA 用户的 TOTP 两步验证配置
+// 📌 EnabledAt 为空表示已生成密钥但尚未用验证码确认，登录时不要求两步验证
+// 📌 Secret 需要参与计算验证码，只能明文（或可逆加密）保存
+type UserMFA struct {
+	UserID    uint   `gorm:"primaryKey;autoIncrement:false"`
+	Secret    string `gorm:"size:64;not null"`
+	EnabledAt *time.Time
+	LastStep  int64 // 最近一次验证成功的时间步，防止验证码重放
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 指定表名
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// IsEnabled 是否已启用两步验证
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MFARecoveryCode 恢复码（丢失验证器时使用，每个只能用一次）
+type MFARecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// ==================== DTO ====================
+
+// MFASetupResponse 开启两步验证的第一步：返回密钥和恢复码（只展示这一次）
+type MFASetupResponse struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFASetupRequest 生成密钥前需要再次输入密码确认身份
+type MFASetupRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// MFAEnableRequest 确认开启两步验证：密码 + 新验证器生成的验证码
+type MFAEnableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFACodeRequest 验证码请求（TOTP 验证码或恢复码）
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFALoginRequest 两步登录第二步
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP 验证码或恢复码
+}
@@ -0,0 +1,88 @@
+// internal/model/rbac.go - 角色权限模型
+//
+// 📌 RBAC: 用户 ←多对多→ 角色 ←多对多→ 权限
+// 📌 权限码采用 "资源:动作" 格式，如 user:read、role:manage
+package model
+
+import (
+	"time"
+)
+
+// 内置角色
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// 内置权限
+const (
+	PermUserRead    = "user:read"
+	PermUserDelete  = "user:delete"
+	PermUserRestore = "user:restore"
+	PermUserPurge   = "user:purge"
+	PermUserStatus  = "user:status"
+	PermUserMFA     = "user:mfa"
+	PermRoleRead    = "role:read"
+	PermRoleManage  = "role:manage"
+	PermAuditRead   = "audit:read"
+	PermSystemLog   = "system:log"
+)
+
+// Role 角色
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	Description string       `json:"description" gorm:"size:200"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// IsBuiltin 是否为内置角色（不允许删除）
+func (r *Role) IsBuiltin() bool {
+	return r.Name == RoleAdmin || r.Name == RoleUser
+}
+
+// Permission 权限
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"uniqueIndex;size:100;not null"`
+	Description string    `json:"description" gorm:"size:200"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// ==================== DTO ====================
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"`
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest 更新角色请求
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions"`
+}
+
+// CreatePermissionRequest 创建权限请求
+type CreatePermissionRequest struct {
+	Code        string `json:"code" binding:"required,min=3,max=100"`
+	Description string `json:"description" binding:"max=200"`
+}
+
+// AssignRolesRequest 分配角色请求
+type AssignRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
@@ -0,0 +1,53 @@
+// internal/model/session.go - 登录会话模型
+package model
+
+import (
+	"time"
+)
+
+// Session 登录会话
+//
+// 📌 每次登录创建一个会话，access token 通过 sid 声明关联到会话
+// 📌 refresh token 只保存 SHA-256 哈希，数据库泄露也无法直接使用
+type Session struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	UserID           uint       `json:"user_id" gorm:"index;not null"`
+	RefreshTokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsActive 会话是否仍然有效（未吊销且未过期）
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RotatedRefreshToken 已被轮换掉的 refresh token
+// 📌 轮换后旧 token 的哈希保留在此表，再次出现说明 token 已泄露（被攻击者或合法用户重复使用），
+// 此时吊销整个会话，攻击者和合法用户手里的 token 同时失效
+type RotatedRefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	SessionID uint      `gorm:"index;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+	RotatedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (RotatedRefreshToken) TableName() string {
+	return "rotated_refresh_tokens"
+}
+
+// ==================== DTO ====================
+
+// RefreshTokenRequest 刷新 token 请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,12 @@
+// internal/model/system.go - 运维接口请求/响应
+package model
+
+// LogLevelRequest 修改日志级别请求
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
+}
+
+// LogLevelResponse 当前日志级别
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
@@ -3,17 +3,36 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 账号状态
+const (
+	UserStatusPending  = "pending"  // 待激活（注册时要求先验证邮箱）
+	UserStatusActive   = "active"   // 正常
+	UserStatusDisabled = "disabled" // 被管理员禁用
+	UserStatusLocked   = "locked"   // 因安全原因被管理员锁定
 )
 
 // User 用户实体
+//
+// 📌 软删除: DeletedAt 非空即视为已删除，GORM 查询默认自动过滤
+// 📌 用户名/邮箱使用部分唯一索引（只约束未删除的行），已删除用户不会永久占用用户名
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"uniqueIndex;size:50;not null"`
-	Email     string    `json:"email" gorm:"uniqueIndex;size:100;not null"`
-	Password  string    `json:"-" gorm:"size:100;not null"`
-	Role      string    `json:"role" gorm:"size:20;default:user"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Username string `json:"username" gorm:"uniqueIndex:idx_users_username_active,where:deleted_at IS NULL;size:50;not null"`
+	Email    string `json:"email" gorm:"uniqueIndex:idx_users_email_active,where:deleted_at IS NULL;size:100;not null"`
+	Password string `json:"-" gorm:"size:100;not null"`
+	Status   string `json:"status" gorm:"size:20;not null;default:active;index"`
+	// 📌 邮箱验证时间，为空表示未验证；修改邮箱后会被清空
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// 📌 为 true 时只能访问个人信息和修改密码接口（初始管理员）
+	MustChangePassword bool           `json:"must_change_password" gorm:"not null;default:false"`
+	Roles              []Role         `json:"roles" gorm:"many2many:user_roles"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // TableName 指定表名
@@ -21,13 +40,47 @@ func (User) TableName() string {
 	return "users"
 }
 
+// RoleNames 返回用户的角色名列表
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PermissionCodes 返回用户全部角色的权限码（去重）
+// 📌 需要预加载 Roles.Permissions
+func (u *User) PermissionCodes() []string {
+	seen := make(map[string]bool)
+	codes := make([]string, 0)
+	for _, role := range u.Roles {
+		for _, perm := range role.Permissions {
+			if !seen[perm.Code] {
+				seen[perm.Code] = true
+				codes = append(codes, perm.Code)
+			}
+		}
+	}
+	return codes
+}
+
 // ==================== DTO ====================
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // 强度由密码策略校验
+}
+
+// CreateAdminRequest 创建管理员（命令行 / 配置引导）
+type CreateAdminRequest struct {
+	Username string
+	Email    string
+	Password string
+	// MustChangePassword 首次登录必须修改密码
+	MustChangePassword bool
 }
 
 // LoginRequest 登录请求
@@ -44,31 +97,71 @@ type UpdateProfileRequest struct {
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// UserQuery 用户列表查询条件
+// 📌 created_from / created_to 格式为 2006-01-02，created_to 包含当天
+// 📌 pagination=cursor 或携带 cursor 时使用游标分页，page 参数被忽略
+// 📌 deleted: exclude（默认）不含已删除 / include 包含已删除 / only 仅已删除
+type UserQuery struct {
+	Pagination  string     `form:"pagination" binding:"omitempty,oneof=offset cursor"`
+	Cursor      string     `form:"cursor"`
+	Page        int        `form:"page"`
+	PageSize    int        `form:"page_size"`
+	Keyword     string     `form:"keyword" binding:"max=100"` // 模糊匹配用户名或邮箱
+	Role        string     `form:"role" binding:"max=50"`
+	Status      string     `form:"status" binding:"omitempty,oneof=pending active disabled locked"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02"`
+	SortBy      string     `form:"sort_by" binding:"omitempty,oneof=id username email created_at"`
+	SortOrder   string     `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	Deleted     string     `form:"deleted" binding:"omitempty,oneof=exclude include only"`
+}
+
+// IsCursorMode 是否使用游标分页
+func (q *UserQuery) IsCursorMode() bool {
+	return q.Pagination == "cursor" || q.Cursor != ""
 }
 
 // UserResponse 用户响应
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID                 uint       `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Roles              []string   `json:"roles"`
+	Status             string     `json:"status"`
+	EmailVerified      bool       `json:"email_verified"`
+	MustChangePassword bool       `json:"must_change_password,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
 }
 
 // ToResponse 转换为响应
 func (u *User) ToResponse() *UserResponse {
-	return &UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
+	resp := &UserResponse{
+		ID:                 u.ID,
+		Username:           u.Username,
+		Email:              u.Email,
+		Roles:              u.RoleNames(),
+		Status:             u.Status,
+		EmailVerified:      u.EmailVerifiedAt != nil,
+		MustChangePassword: u.MustChangePassword,
+		CreatedAt:          u.CreatedAt,
+	}
+	if u.DeletedAt.Valid {
+		resp.DeletedAt = &u.DeletedAt.Time
 	}
+	return resp
 }
 
 // LoginResponse 登录响应
+// 📌 开启两步验证的用户第一步只返回 mfa_required + mfa_token，expires_in 为 mfa_token 有效期
 type LoginResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
+	Token        string        `json:"token,omitempty"`         // access token
+	RefreshToken string        `json:"refresh_token,omitempty"` // 用于换取新的 access token
+	ExpiresIn    int64         `json:"expires_in"`              // access token 剩余秒数
+	User         *UserResponse `json:"user,omitempty"`
+	MFARequired  bool          `json:"mfa_required,omitempty"`
+	MFAToken     string        `json:"mfa_token,omitempty"` // 提交给 /api/login/mfa
 }
@@ -0,0 +1,221 @@
+// internal/password/hasher.go - 密码哈希
+//
+// 📌 Hasher 接口屏蔽具体算法，业务代码不再直接依赖 bcrypt
+// 📌 支持 bcrypt 和 argon2id，由配置选择新密码使用的算法
+// 📌 已有哈希按前缀识别算法（$2a$ / $argon2id$），旧算法或低参数的哈希在登录成功后透明升级
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"user-management/internal/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("无法识别的密码哈希格式")
+
+// Hasher 密码哈希接口
+type Hasher interface {
+	// Hash 计算密码哈希
+	Hash(password string) (string, error)
+	// Verify 校验密码，哈希格式错误时返回 error
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash 哈希是否使用了过时的算法或参数
+	NeedsRehash(encoded string) bool
+}
+
+// algorithm 具体算法实现，额外提供格式识别
+type algorithm interface {
+	Hasher
+	matches(encoded string) bool
+}
+
+// NewHasher 根据配置创建哈希器
+// 📌 新密码使用配置的算法，校验时兼容全部已知算法
+func NewHasher(cfg *config.PasswordHashConfig) (Hasher, error) {
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+	argon2Hasher := NewArgon2idHasher(cfg.Argon2MemoryKB, cfg.Argon2Iterations, cfg.Argon2Parallelism)
+
+	var preferred algorithm
+	switch cfg.Algorithm {
+	case "", "bcrypt":
+		preferred = bcryptHasher
+	case "argon2id":
+		preferred = argon2Hasher
+	default:
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", cfg.Algorithm)
+	}
+
+	return &multiHasher{
+		preferred:  preferred,
+		algorithms: []algorithm{bcryptHasher, argon2Hasher},
+	}, nil
+}
+
+type multiHasher struct {
+	preferred  algorithm
+	algorithms []algorithm
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *multiHasher) Verify(password, encoded string) (bool, error) {
+	for _, a := range h.algorithms {
+		if a.matches(encoded) {
+			return a.Verify(password, encoded)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+func (h *multiHasher) NeedsRehash(encoded string) bool {
+	if !h.preferred.matches(encoded) {
+		return true
+	}
+	return h.preferred.NeedsRehash(encoded)
+}
+
+// ==================== bcrypt ====================
+
+// BcryptHasher bcrypt 实现
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.cost
+}
+
+func (h *BcryptHasher) matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// ==================== argon2id ====================
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Argon2idHasher argon2id 实现
+// 📌 编码格式（PHC）: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	memory      uint32 // KB
+	iterations  uint32
+	parallelism uint8
+}
+
+func NewArgon2idHasher(memoryKB, iterations, parallelism int) *Argon2idHasher {
+	if memoryKB <= 0 {
+		memoryKB = 64 * 1024
+	}
+	if iterations <= 0 {
+		iterations = 3
+	}
+	if parallelism <= 0 || parallelism > 255 {
+		parallelism = 2
+	}
+	return &Argon2idHasher{
+		memory:      uint32(memoryKB),
+		iterations:  uint32(iterations),
+		parallelism: uint8(parallelism),
+	}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.iterations, h.memory, h.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.memory < h.memory ||
+		params.iterations < h.iterations ||
+		params.parallelism < h.parallelism
+}
+
+func (h *Argon2idHasher) matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+
+	return params, salt, key, nil
+}
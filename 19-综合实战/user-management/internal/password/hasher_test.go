@@ -0,0 +1,113 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"user-management/internal/config"
+)
+
+// 测试使用低参数，避免耗时
+var (
+	testBcrypt = &config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 5}
+	testArgon2 = &config.PasswordHashConfig{Algorithm: "argon2id", BcryptCost: 5, Argon2MemoryKB: 64, Argon2Iterations: 2, Argon2Parallelism: 1}
+)
+
+func mustHasher(t *testing.T, cfg *config.PasswordHashConfig) Hasher {
+	t.Helper()
+	h, err := NewHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func mustHash(t *testing.T, h Hasher, password string) string {
+	t.Helper()
+	encoded, err := h.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestHasherVerify(t *testing.T) {
+	for _, cfg := range []*config.PasswordHashConfig{testBcrypt, testArgon2} {
+		t.Run(cfg.Algorithm, func(t *testing.T) {
+			h := mustHasher(t, cfg)
+			encoded := mustHash(t, h, "Tom@2024!pw")
+			if !strings.HasPrefix(encoded, map[string]string{"bcrypt": "$2a$", "argon2id": "$argon2id$"}[cfg.Algorithm]) {
+				t.Fatalf("encoded = %q", encoded)
+			}
+			if ok, err := h.Verify("Tom@2024!pw", encoded); !ok || err != nil {
+				t.Errorf("Verify(correct) = (%v, %v), want (true, nil)", ok, err)
+			}
+			if ok, err := h.Verify("wrong", encoded); ok || err != nil {
+				t.Errorf("Verify(wrong) = (%v, %v), want (false, nil)", ok, err)
+			}
+			if h.NeedsRehash(encoded) {
+				t.Error("NeedsRehash = true for a fresh hash")
+			}
+		})
+	}
+}
+
+func TestHasherVerifyAcrossAlgorithms(t *testing.T) {
+	// 切换算法后旧哈希仍能校验
+	oldHash := mustHash(t, mustHasher(t, testBcrypt), "Tom@2024!pw")
+	h := mustHasher(t, testArgon2)
+	if ok, err := h.Verify("Tom@2024!pw", oldHash); !ok || err != nil {
+		t.Fatalf("Verify(bcrypt hash) = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"空串", ""},
+		{"明文", "Tom@2024!pw"},
+		{"未知算法", "$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 参数缺失", "$argon2id$v=19$c2FsdA$aGFzaA"},
+		{"argon2id 版本不符", "$argon2id$v=16$m=64,t=2,p=1$c2FsdA$aGFzaA"},
+		{"argon2id 哈希为空", "$argon2id$v=19$m=64,t=2,p=1$c2FsdA$"},
+	}
+	for _, tt := range tests {
+		if ok, err := h.Verify("Tom@2024!pw", tt.encoded); ok || !errors.Is(err, ErrUnknownHashFormat) {
+			t.Errorf("%s: Verify = (%v, %v), want (false, ErrUnknownHashFormat)", tt.name, ok, err)
+		}
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	bcryptLow := mustHash(t, mustHasher(t, &config.PasswordHashConfig{BcryptCost: 4}), "pw")
+	bcryptCurrent := mustHash(t, mustHasher(t, testBcrypt), "pw")
+	argon2Low := mustHash(t, mustHasher(t, &config.PasswordHashConfig{Algorithm: "argon2id", Argon2MemoryKB: 32, Argon2Iterations: 1, Argon2Parallelism: 1}), "pw")
+	argon2Current := mustHash(t, mustHasher(t, testArgon2), "pw")
+
+	tests := []struct {
+		name    string
+		cfg     *config.PasswordHashConfig
+		encoded string
+		want    bool
+	}{
+		{"bcrypt 成本不变", testBcrypt, bcryptCurrent, false},
+		{"bcrypt 成本提高", testBcrypt, bcryptLow, true},
+		{"bcrypt 成本降低不回退", &config.PasswordHashConfig{BcryptCost: 4}, bcryptCurrent, false},
+		{"bcrypt 升级为 argon2id", testArgon2, bcryptCurrent, true},
+		{"argon2id 参数不变", testArgon2, argon2Current, false},
+		{"argon2id 参数提高", testArgon2, argon2Low, true},
+		{"argon2id 回退为 bcrypt", testBcrypt, argon2Current, true},
+		{"无法识别的哈希", testBcrypt, "plain", true},
+	}
+	for _, tt := range tests {
+		if got := mustHasher(t, tt.cfg).NeedsRehash(tt.encoded); got != tt.want {
+			t.Errorf("%s: NeedsRehash = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewHasherRejectsUnknownAlgorithm(t *testing.T) {
+	if _, err := NewHasher(&config.PasswordHashConfig{Algorithm: "md5"}); err == nil {
+		t.Fatal("NewHasher(md5) succeeded")
+	}
+}
@@ -0,0 +1,154 @@
+// internal/password/policy.go - 密码策略
+//
+// 📌 规则全部可配置: 长度、字符类别、不能与用户名相同、常见弱密码黑名单
+// 📌 一次返回全部不满足的规则，前端可以逐条提示
+// 📌 bcrypt 只使用前 72 字节，超出部分会被静默截断，因此长度上限按字节计算且不超过 72
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+)
+
+// BcryptMaxBytes bcrypt 可处理的最大密码字节数
+const BcryptMaxBytes = 72
+
+var ErrPolicyViolation = apperr.PasswordPolicy
+
+// Violation 单条规则违反
+type Violation struct {
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// PolicyError 密码策略错误，包含全部违反的规则
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return ErrPolicyViolation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// FieldErrors 每条违反的规则作为 password 字段的一条详情
+func (e *PolicyError) FieldErrors() []apperr.FieldError {
+	details := make([]apperr.FieldError, len(e.Violations))
+	for i, v := range e.Violations {
+		details[i] = apperr.FieldError{Field: "password", Rule: v.Rule, Param: v.Param, Message: v.Message}
+	}
+	return details
+}
+
+// Policy 密码策略
+type Policy struct {
+	config *config.PasswordPolicyConfig
+	common map[string]bool
+}
+
+// NewPolicy 创建密码策略，配置了黑名单文件时一并加载
+func NewPolicy(cfg *config.PasswordPolicyConfig) (*Policy, error) {
+	p := &Policy{config: cfg, common: make(map[string]bool)}
+
+	if cfg.CommonPasswordsFile != "" {
+		if err := p.loadCommonPasswords(cfg.CommonPasswordsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Validate 校验密码，全部通过返回 nil，否则返回 *PolicyError
+func (p *Policy) Validate(password, username string) error {
+	var violations []Violation
+	add := func(rule, param, message string) {
+		violations = append(violations, Violation{Rule: rule, Param: param, Message: message})
+	}
+
+	if n := utf8.RuneCountInString(password); n < p.config.MinLength {
+		add("min_length", strconv.Itoa(p.config.MinLength), fmt.Sprintf("密码至少 %d 位", p.config.MinLength))
+	}
+	if maxBytes := p.maxBytes(); len(password) > maxBytes {
+		add("max_bytes", strconv.Itoa(maxBytes), fmt.Sprintf("密码不能超过 %d 字节", maxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+	if p.config.RequireUpper && !hasUpper {
+		add("upper", "", "密码需包含大写字母")
+	}
+	if p.config.RequireLower && !hasLower {
+		add("lower", "", "密码需包含小写字母")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		add("digit", "", "密码需包含数字")
+	}
+	if p.config.RequireSpecial && !hasSpecial {
+		add("special", "", "密码需包含特殊字符")
+	}
+
+	if p.config.DisallowUsername && username != "" && strings.EqualFold(password, username) {
+		add("username", "", "密码不能与用户名相同")
+	}
+	if p.common[strings.ToLower(password)] {
+		add("common", "", "密码过于常见，请更换")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *Policy) maxBytes() int {
+	if p.config.MaxBytes <= 0 || p.config.MaxBytes > BcryptMaxBytes {
+		return BcryptMaxBytes
+	}
+	return p.config.MaxBytes
+}
+
+// loadCommonPasswords 加载常见密码黑名单，每行一个，# 开头为注释
+func (p *Policy) loadCommonPasswords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("加载常见密码列表失败: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.common[strings.ToLower(line)] = true
+	}
+	return scanner.Err()
+}
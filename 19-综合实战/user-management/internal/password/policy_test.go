@@ -0,0 +1,94 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"user-management/internal/config"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	common := filepath.Join(t.TempDir(), "common.txt")
+	if err := os.WriteFile(common, []byte("# 常见密码\n\nPassword1!\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicy(&config.PasswordPolicyConfig{
+		MinLength:           8,
+		MaxBytes:            100, // 超过 bcrypt 上限，按 72 计算
+		RequireUpper:        true,
+		RequireLower:        true,
+		RequireDigit:        true,
+		RequireSpecial:      true,
+		DisallowUsername:    true,
+		CommonPasswordsFile: common,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		password  string
+		username  string
+		wantRules []string
+	}{
+		{"全部满足", "Tom@2024!pw", "tom", nil},
+		{"过短", "Ab1!", "tom", []string{"min_length"}},
+		{"多字节字符按字符数计算长度", "密码密码Ab1!", "tom", nil},
+		{"超过 72 字节", "Ab1!" + strings.Repeat("x", 69), "tom", []string{"max_bytes"}},
+		{"缺少大写", "tom@2024!pw", "tom", []string{"upper"}},
+		{"缺少小写", "TOM@2024!PW", "tom", []string{"lower"}},
+		{"缺少数字", "Tom@abcd!pw", "tom", []string{"digit"}},
+		{"缺少特殊字符", "Tom2024xpw", "tom", []string{"special"}},
+		{"与用户名相同（忽略大小写）", "Jerry@2024!", "JERRY@2024!", []string{"username"}},
+		{"常见密码（忽略大小写）", "password1!", "tom", []string{"upper", "common"}},
+		{"全部返回", "abc", "abc", []string{"min_length", "upper", "digit", "special", "username"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.username)
+			if tt.wantRules == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) || !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("err = %v, want *PolicyError", err)
+			}
+			var rules []string
+			for _, v := range policyErr.Violations {
+				rules = append(rules, v.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", rules, tt.wantRules)
+			}
+			if details := policyErr.FieldErrors(); len(details) != len(rules) || details[0].Field != "password" {
+				t.Errorf("FieldErrors = %+v", details)
+			}
+		})
+	}
+}
+
+func TestPolicyDefaults(t *testing.T) {
+	// 未开启的规则不检查
+	policy, err := NewPolicy(&config.PasswordPolicyConfig{MinLength: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Validate("abcdef", "abcdef"); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if err := policy.Validate(strings.Repeat("a", BcryptMaxBytes+1), ""); err == nil {
+		t.Error("password over 72 bytes accepted")
+	}
+
+	if _, err := NewPolicy(&config.PasswordPolicyConfig{CommonPasswordsFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("missing common passwords file accepted")
+	}
+}
@@ -0,0 +1,232 @@
+// internal/redact/redact.go - 日志脱敏
+//
+// 📌 三类规则，内置默认值始终生效，配置 log.redact 在此基础上追加:
+//   - JSON 路径: 点号分隔，* 匹配任意一个键，** 匹配任意层级，数组元素自动展开，键名不区分大小写
+//     如 "password" 只匹配顶层，"data.token" 匹配响应中的 token，"**.secret" 匹配任意位置
+//   - 请求头: 不区分大小写
+//   - 查询参数 / 表单字段: 按键名匹配
+//   - 敏感接口: 按路径前缀匹配，无法解析的 body 一律不记录原文
+//
+// 📌 数值、布尔与 null 不脱敏: 本服务的凭据均为字符串，业务码 code 等数值字段保留便于排查
+// 📌 无法完整解析的 JSON（过大或格式错误）整体不记录，宁可少记也不能漏脱敏
+// 📌 handler 使用 ShouldBindJSON 绑定，不校验 Content-Type，因此任何 body 都先按 JSON 尝试脱敏
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+	"user-management/internal/config"
+)
+
+// Mask 脱敏后的占位值
+const Mask = "***"
+
+// DefaultMaxBytes 单个 body 记录的默认最大字节数
+const DefaultMaxBytes = 4096
+
+// 内置规则
+var (
+	DefaultJSONPaths = []string{
+		"**.password", "**.old_password", "**.new_password",
+		"**.token", "**.refresh_token", "**.mfa_token", "**.code",
+		"**.secret", "**.otpauth_uri", "**.recovery_codes",
+	}
+	DefaultHeaders   = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	DefaultQueryKeys = []string{"token", "refresh_token", "password", "code"}
+	// DefaultSensitivePaths 认证、密码与两步验证相关接口
+	DefaultSensitivePaths = []string{
+		"/api/register", "/api/login", "/api/token", "/api/logout",
+		"/api/password", "/api/verify-email", "/api/mfa", "/api/admin/users",
+	}
+)
+
+// Redactor 脱敏器，创建后只读，可并发使用
+type Redactor struct {
+	jsonPaths [][]string
+	headers   map[string]bool
+	queryKeys map[string]bool
+	sensitive []string
+	maxBytes  int
+}
+
+// New 合并内置规则与配置规则，maxBytes <= 0 时使用 DefaultMaxBytes
+func New(cfg *config.RedactConfig, maxBytes int) *Redactor {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	r := &Redactor{
+		headers:   make(map[string]bool),
+		queryKeys: make(map[string]bool),
+		maxBytes:  maxBytes,
+	}
+	for _, path := range append(append([]string(nil), DefaultJSONPaths...), cfg.JSONPaths...) {
+		r.jsonPaths = append(r.jsonPaths, strings.Split(path, "."))
+	}
+	for _, name := range append(append([]string(nil), DefaultHeaders...), cfg.Headers...) {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, key := range append(append([]string(nil), DefaultQueryKeys...), cfg.QueryKeys...) {
+		r.queryKeys[key] = true
+	}
+	r.sensitive = append(append(r.sensitive, DefaultSensitivePaths...), cfg.SensitivePaths...)
+	return r
+}
+
+// Sensitive 判断请求路径是否属于敏感接口
+func (r *Redactor) Sensitive(path string) bool {
+	for _, prefix := range r.sensitive {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers 返回脱敏后的请求头，多个值以逗号连接
+func (r *Redactor) Headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			out[name] = Mask
+		} else {
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// Query 对 URL 查询串（或表单 body）中的敏感参数脱敏，保留参数顺序
+func (r *Redactor) Query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && r.queryKeys[name] && hasValue {
+			pairs[i] = key + "=" + Mask
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// Body 返回可写入日志的 body
+// 📌 complete 为 false 表示 body 超出采集上限只拿到了前一部分
+// 📌 path 属于敏感接口时，只记录能解析并脱敏的 JSON / 表单，其余内容只记录类型和大小
+func (r *Redactor) Body(path, contentType string, body []byte, complete bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	// 不论 Content-Type 如何，能解析为 JSON 的 body 都按 JSON 规则脱敏
+	if complete {
+		if masked, err := r.JSON(body); err == nil {
+			return r.truncate(masked, len(masked))
+		}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || looksLikeJSON(body):
+		if !complete {
+			return fmt.Sprintf("[JSON 过大未记录: %d+ bytes]", len(body))
+		}
+		return fmt.Sprintf("[JSON 格式错误未记录: %d bytes]", len(body))
+	case mediaType == "application/x-www-form-urlencoded":
+		if !complete {
+			return fmt.Sprintf("[表单过大未记录: %d+ bytes]", len(body))
+		}
+		masked := r.Query(string(body))
+		return r.truncate([]byte(masked), len(masked))
+	case r.Sensitive(path):
+		return fmt.Sprintf("[敏感接口内容未记录: %s, %d bytes]", mediaType, len(body))
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml":
+		return r.truncate(body, len(body))
+	default:
+		return fmt.Sprintf("[二进制内容未记录: %s, %d bytes]", mediaType, len(body))
+	}
+}
+
+// looksLikeJSON 首个非空白字符为 { 或 [ 时视为 JSON（可能被截断或格式错误）
+func looksLikeJSON(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// JSON 按路径规则脱敏，返回重新编码的 JSON（对象键按字母序）
+func (r *Redactor) JSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // 保留数值原样
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	for _, path := range r.jsonPaths {
+		v = maskPath(v, path)
+	}
+	return json.Marshal(v)
+}
+
+func (r *Redactor) truncate(b []byte, total int) string {
+	if len(b) <= r.maxBytes {
+		return string(b)
+	}
+	cut := r.maxBytes
+	for cut > 0 && !utf8.RuneStart(b[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[已截断，共 %d bytes]", b[:cut], total)
+}
+
+// maskPath 将 v 中匹配 path 的值替换为 Mask
+func maskPath(v interface{}, path []string) interface{} {
+	if arr, ok := v.([]interface{}); ok && len(path) > 0 {
+		for i := range arr {
+			arr[i] = maskPath(arr[i], path)
+		}
+		return arr
+	}
+	if len(path) == 0 {
+		return maskValue(v)
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	switch seg := path[0]; seg {
+	case "**":
+		// 匹配零层，再对每个子节点保留 ** 继续匹配
+		v = maskPath(obj, path[1:])
+		for key := range obj {
+			obj[key] = maskPath(obj[key], path)
+		}
+	case "*":
+		for key := range obj {
+			obj[key] = maskPath(obj[key], path[1:])
+		}
+	default:
+		// 📌 encoding/json 绑定时键名不区分大小写，{"PASSWORD": ...} 同样会绑定到 password 字段
+		for key := range obj {
+			if strings.EqualFold(key, seg) {
+				obj[key] = maskPath(obj[key], path[1:])
+			}
+		}
+	}
+	return v
+}
+
+func maskValue(v interface{}) interface{} {
+	switch v.(type) {
+	case json.Number, bool, nil:
+		return v
+	default:
+		return Mask
+	}
+}
@@ -0,0 +1,131 @@
+package redact
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"user-management/internal/config"
+)
+
+func TestJSON(t *testing.T) {
+	r := New(&config.RedactConfig{JSONPaths: []string{"data.phone", "*.id_card", "**.bank_account"}}, 0)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"顶层字段", `{"username":"tom","password":"p"}`, `{"password":"***","username":"tom"}`},
+		{"大写键名", `{"PASSWORD":"p"}`, `{"PASSWORD":"***"}`},
+		{"混合大小写", `{"New_Password":"p","Old_PASSWORD":"q"}`, `{"New_Password":"***","Old_PASSWORD":"***"}`},
+		{"** 匹配任意层级", `{"data":{"user":{"token":"t"}}}`, `{"data":{"user":{"token":"***"}}}`},
+		{"配置的嵌套路径", `{"data":{"phone":"138","name":"tom"},"phone":"139"}`, `{"data":{"name":"tom","phone":"***"},"phone":"139"}`},
+		{"配置的嵌套路径不区分大小写", `{"Data":{"Phone":"138"}}`, `{"Data":{"Phone":"***"}}`},
+		{"* 匹配一层", `{"a":{"id_card":"x"},"id_card":"y","b":{"c":{"id_card":"z"}}}`, `{"a":{"id_card":"***"},"b":{"c":{"id_card":"z"}},"id_card":"y"}`},
+		{"配置的 ** 路径", `{"a":[{"b":{"bank_account":"x"}}]}`, `{"a":[{"b":{"bank_account":"***"}}]}`},
+		{"数组元素展开", `[{"token":"a"},{"token":"b"}]`, `[{"token":"***"},{"token":"***"}]`},
+		{"数组值整体脱敏", `{"recovery_codes":["a","b"]}`, `{"recovery_codes":"***"}`},
+		{"数值与布尔保留", `{"code":0,"data":{"secret":true,"token":null}}`, `{"code":0,"data":{"secret":true,"token":null}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.JSON([]byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("JSON(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	r := New(&config.RedactConfig{}, 20)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		complete    bool
+		want        string
+	}{
+		{"JSON 脱敏", "/api/login", "application/json", `{"password":"p"}`, true, `{"password":"***"}`},
+		{"任意 Content-Type 的 JSON 都脱敏", "/api/login", "text/plain", `{"Password":"p"}`, true, `{"Password":"***"}`},
+		{"不完整的 JSON 不记录", "/api/users", "application/json", `{"password":"p`, false, "[JSON 过大未记录: 14+ bytes]"},
+		{"格式错误的 JSON 不记录", "/api/users", "", `{"password":`, true, "[JSON 格式错误未记录: 12 bytes]"},
+		{"表单脱敏", "/api/users", "application/x-www-form-urlencoded", "a=1&token=x", true, "a=1&token=***"},
+		{"敏感接口的文本不记录", "/api/password/reset", "text/plain", "password=p", true, "[敏感接口内容未记录: text/plain, 10 bytes]"},
+		{"普通文本", "/api/users", "text/plain; charset=utf-8", "hello", true, "hello"},
+		{"二进制", "/api/users", "image/png", "\x89PNG", true, "[二进制内容未记录: image/png, 4 bytes]"},
+		{"按 UTF-8 边界截断", "/api/users", "text/plain", strings.Repeat("日志", 4), true, "日志日志日志...[已截断，共 24 bytes]"},
+		{"空 body", "/api/login", "application/json", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Body(tt.path, tt.contentType, []byte(tt.body), tt.complete)
+			if got != tt.want {
+				t.Errorf("Body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	r := New(&config.RedactConfig{SensitivePaths: []string{"/api/internal/"}}, 0)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/api/login", true},
+		{"/api/login/mfa", true},
+		{"/api/loginx", false},
+		{"/api/mfa/totp/setup", true},
+		{"/api/internal/keys", true},
+		{"/api/profile", false},
+	}
+	for _, tt := range tests {
+		if got := r.Sensitive(tt.path); got != tt.want {
+			t.Errorf("Sensitive(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	r := New(&config.RedactConfig{Headers: []string{"x-signature"}}, 0)
+	h := http.Header{}
+	h.Set("Authorization", "Bearer abc")
+	h.Set("X-Signature", "sig")
+	h.Add("Accept", "a")
+	h.Add("Accept", "b")
+
+	got := r.Headers(h)
+	want := map[string]string{"Authorization": Mask, "X-Signature": Mask, "Accept": "a, b"}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("Headers[%s] = %q, want %q", name, got[name], value)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := New(&config.RedactConfig{QueryKeys: []string{"ticket"}}, 0)
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"token=abc&page=1", "token=***&page=1"},
+		{"page=1&ticket=t&code=123", "page=1&ticket=***&code=***"},
+		{"refresh%5Ftoken=x", "refresh%5Ftoken=***"},
+		{"token", "token"},
+	}
+	for _, tt := range tests {
+		if got := r.Query(tt.raw); got != tt.want {
+			t.Errorf("Query(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// internal/repository/audit_repository.go - 审计日志数据访问层
+package repository
+
+import (
+	"context"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository 审计日志仓储接口
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEvent) error
+	FindAll(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, int64, error)
+	// FindForExport 导出查询，最多返回 limit 条（按时间倒序）
+	FindForExport(ctx context.Context, query *model.AuditQuery, limit int) ([]*model.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *model.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *auditRepository) FindAll(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, int64, error) {
+	var events []*model.AuditEvent
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&model.AuditEvent{}).Scopes(auditFilter(query)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := r.db.WithContext(ctx).Scopes(auditFilter(query)).
+		Order("created_at DESC, id DESC").
+		Offset(offset).Limit(query.PageSize).
+		Find(&events).Error
+
+	return events, total, err
+}
+
+func (r *auditRepository) FindForExport(ctx context.Context, query *model.AuditQuery, limit int) ([]*model.AuditEvent, error) {
+	var events []*model.AuditEvent
+	err := r.db.WithContext(ctx).Scopes(auditFilter(query)).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// auditFilter 审计查询过滤条件
+func auditFilter(query *model.AuditQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.ActorID != 0 {
+			db = db.Where("actor_id = ?", query.ActorID)
+		}
+		if query.TargetID != 0 {
+			db = db.Where("target_id = ?", query.TargetID)
+		}
+		if query.Action != "" {
+			db = db.Where("action = ?", query.Action)
+		}
+		if query.IP != "" {
+			db = db.Where("ip = ?", query.IP)
+		}
+		if query.RequestID != "" {
+			db = db.Where("request_id = ?", query.RequestID)
+		}
+		if query.From != nil {
+			db = db.Where("created_at >= ?", *query.From)
+		}
+		if query.To != nil {
+			db = db.Where("created_at < ?", query.To.AddDate(0, 0, 1))
+		}
+		return db
+	}
+}
@@ -0,0 +1,78 @@
+// internal/repository/errors.go - 数据库错误转换
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateKey 唯一约束冲突
+var ErrDuplicateKey = errors.New("唯一约束冲突")
+
+// DuplicateKeyError 唯一约束冲突，Column 为冲突的列（无法识别时为空）
+// 📌 服务层据此转换为 ErrUsernameExists 等业务错误，而不是返回 500
+type DuplicateKeyError struct {
+	Column string
+	Err    error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("唯一约束冲突 (%s): %v", e.Column, e.Err)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// translateError 将唯一约束冲突转换为 *DuplicateKeyError，其他错误原样返回
+// 📌 只根据冲突的约束名（索引名）识别冲突列，兼容各数据库的报错格式:
+//   - SQLite:     UNIQUE constraint failed: users.username
+//   - PostgreSQL: duplicate key value violates unique constraint "idx_users_username_active"（取 PgError.ConstraintName）
+//   - MySQL:      Duplicate entry 'tom' for key 'users.idx_users_username_active'
+//
+// 📌 MySQL 的报错中包含冲突的值，不能在整条信息里查找列名，否则值为 username@x.com 的邮箱冲突会被识别为用户名冲突
+func translateError(err error, columns ...string) error {
+	if err == nil || !isUniqueViolation(err) {
+		return err
+	}
+
+	constraint := constraintName(err)
+	for _, column := range columns {
+		if constraint != "" && strings.Contains(constraint, column) {
+			return &DuplicateKeyError{Column: column, Err: err}
+		}
+	}
+	return &DuplicateKeyError{Err: err}
+}
+
+// constraintName 返回冲突的约束名（SQLite 为 表.列），无法识别时返回空串
+func constraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+
+	msg := err.Error()
+	// 📌 冲突的值本身可能包含 "for key '"，约束名总在最后，取最后一次出现的位置
+	if i := strings.LastIndex(msg, "for key '"); i >= 0 {
+		return strings.TrimSuffix(msg[i+len("for key '"):], "'")
+	}
+	if _, after, ok := strings.Cut(msg, "UNIQUE constraint failed: "); ok {
+		return after
+	}
+	return ""
+}
+
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value") ||
+		strings.Contains(msg, "Duplicate entry")
+}
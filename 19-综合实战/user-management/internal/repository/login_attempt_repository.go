@@ -0,0 +1,77 @@
+// internal/repository/login_attempt_repository.go - 登录失败记录数据访问层
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository 登录失败记录仓储接口
+// 📌 默认存数据库，多实例部署时计数共享；也可替换为 Redis 等实现
+type LoginAttemptRepository interface {
+	// Find 查询记录，不存在时返回 nil, nil
+	Find(ctx context.Context, key string) (*model.LoginAttempt, error)
+	// Increment 原子地累加失败次数并返回累加后的记录
+	// 📌 上次失败早于 windowStart 时计数从 1 重新开始并清除锁定
+	Increment(ctx context.Context, key string, now, windowStart time.Time) (*model.LoginAttempt, error)
+	// Lock 设置锁定截止时间，只会延长不会缩短（并发失败时以较长的锁定为准）
+	Lock(ctx context.Context, key string, until time.Time) error
+	Delete(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Find(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := r.db.WithContext(ctx).Where("attempt_key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Increment 使用 upsert（INSERT ... ON CONFLICT DO UPDATE / ON DUPLICATE KEY UPDATE）在数据库内完成累加
+// 📌 先查后改会在并发失败时丢失计数，且两个首次失败会同时 INSERT 触发唯一约束冲突
+// 📌 MySQL 按顺序求值赋值表达式，last_failed_at 必须最后赋值，前面的 CASE 才能读到旧值
+func (r *loginAttemptRepository) Increment(ctx context.Context, key string, now, windowStart time.Time) (*model.LoginAttempt, error) {
+	db := r.db.WithContext(ctx)
+	attempt := &model.LoginAttempt{Key: key, Failures: 1, LastFailedAt: now, UpdatedAt: now}
+	expired := "login_attempts.last_failed_at < ?"
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: []clause.Assignment{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("CASE WHEN "+expired+" THEN 1 ELSE login_attempts.failures + 1 END", windowStart)},
+			{Column: clause.Column{Name: "locked_until"}, Value: gorm.Expr("CASE WHEN "+expired+" THEN NULL ELSE login_attempts.locked_until END", windowStart)},
+			{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+			{Column: clause.Column{Name: "updated_at"}, Value: now},
+		},
+	}).Create(attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Find(ctx, key)
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("attempt_key = ? AND (locked_until IS NULL OR locked_until < ?)", key, until).
+		Update("locked_until", until).Error
+}
+
+func (r *loginAttemptRepository) Delete(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("attempt_key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"user-management/internal/testutil"
+)
+
+func TestLoginAttemptRepositoryIncrement(t *testing.T) {
+	repo := NewLoginAttemptRepository(testutil.NewDB(t))
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 15 * time.Minute
+
+	tests := []struct {
+		name string
+		at   time.Duration // 相对 start 的失败时间
+		want int
+	}{
+		{"首次失败", 0, 1},
+		{"窗口内累加", time.Minute, 2},
+		{"窗口内继续累加", 2 * time.Minute, 3},
+		{"超过窗口后重新计数", 2*time.Minute + window + time.Second, 1},
+	}
+	for _, tt := range tests {
+		now := start.Add(tt.at)
+		attempt, err := repo.Increment(ctx, "user:tom", now, now.Add(-window))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if attempt.Failures != tt.want {
+			t.Errorf("%s: Failures = %d, want %d", tt.name, attempt.Failures, tt.want)
+		}
+		if !attempt.LastFailedAt.Equal(now) {
+			t.Errorf("%s: LastFailedAt = %v, want %v", tt.name, attempt.LastFailedAt, now)
+		}
+	}
+}
+
+func TestLoginAttemptRepositoryLock(t *testing.T) {
+	repo := NewLoginAttemptRepository(testutil.NewDB(t))
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := repo.Increment(ctx, "ip:1.2.3.4", now, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		until time.Time
+		want  time.Time
+	}{
+		{"首次锁定", now.Add(time.Minute), now.Add(time.Minute)},
+		{"延长锁定", now.Add(2 * time.Minute), now.Add(2 * time.Minute)},
+		{"不会缩短已有锁定", now.Add(30 * time.Second), now.Add(2 * time.Minute)},
+	}
+	for _, tt := range tests {
+		if err := repo.Lock(ctx, "ip:1.2.3.4", tt.until); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		attempt, err := repo.Find(ctx, "ip:1.2.3.4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attempt.LockedUntil == nil || !attempt.LockedUntil.Equal(tt.want) {
+			t.Errorf("%s: LockedUntil = %v, want %v", tt.name, attempt.LockedUntil, tt.want)
+		}
+	}
+
+	if err := repo.Delete(ctx, "ip:1.2.3.4"); err != nil {
+		t.Fatal(err)
+	}
+	if attempt, err := repo.Find(ctx, "ip:1.2.3.4"); err != nil || attempt != nil {
+		t.Fatalf("Find after delete = (%v, %v), want (nil, nil)", attempt, err)
+	}
+}
@@ -0,0 +1,104 @@
+// internal/repository/mfa_repository.go - 两步验证数据访问层
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMFANotFound          = errors.New("未设置两步验证")
+	ErrRecoveryCodeNotFound = errors.New("恢复码无效")
+	ErrMFAStepReused        = errors.New("验证码已使用")
+)
+
+// MFARepository 两步验证仓储接口
+type MFARepository interface {
+	FindByUserID(ctx context.Context, userID uint) (*model.UserMFA, error)
+	Save(ctx context.Context, mfa *model.UserMFA) error
+	// Delete 删除两步验证配置及全部恢复码
+	Delete(ctx context.Context, userID uint) error
+	// AdvanceStep 记录已使用的时间步，step 不大于已记录值时返回 ErrMFAStepReused
+	AdvanceStep(ctx context.Context, userID uint, step int64) error
+	// ReplaceRecoveryCodes 用新的恢复码（哈希）替换旧的
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error
+	// UseRecoveryCode 消耗一个恢复码，不存在或已使用时返回 ErrRecoveryCodeNotFound
+	UseRecoveryCode(ctx context.Context, userID uint, hash string) error
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID uint) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	err := r.db.WithContext(ctx).First(&mfa, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFANotFound
+	}
+	return &mfa, err
+}
+
+func (r *mfaRepository) Save(ctx context.Context, mfa *model.UserMFA) error {
+	return r.db.WithContext(ctx).Save(mfa).Error
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error
+	})
+}
+
+func (r *mfaRepository) AdvanceStep(ctx context.Context, userID uint, step int64) error {
+	// 📌 条件更新保证并发请求中同一时间步只有一个能成功
+	result := r.db.WithContext(ctx).Model(&model.UserMFA{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFAStepReused
+	}
+	return nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]model.MFARecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = model.MFARecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string) error {
+	result := r.db.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// internal/repository/rbac_repository.go - 角色权限数据访问层
+package repository
+
+import (
+	"context"
+	"errors"
+	"user-management/internal/apperr"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRoleNotFound       = apperr.RoleNotFound
+	ErrPermissionNotFound = apperr.PermissionNotFound
+)
+
+// RBACRepository 角色权限仓储接口
+type RBACRepository interface {
+	CreateRole(ctx context.Context, role *model.Role) error
+	FindRoleByID(ctx context.Context, id uint) (*model.Role, error)
+	FindRoleByName(ctx context.Context, name string) (*model.Role, error)
+	FindRoles(ctx context.Context) ([]*model.Role, error)
+	FindRolesByNames(ctx context.Context, names []string) ([]model.Role, error)
+	UpdateRole(ctx context.Context, role *model.Role) error
+	ReplaceRolePermissions(ctx context.Context, role *model.Role, permissions []model.Permission) error
+	DeleteRole(ctx context.Context, id uint) error
+	ExistsRoleByName(ctx context.Context, name string) (bool, error)
+
+	CreatePermission(ctx context.Context, permission *model.Permission) error
+	FindPermissions(ctx context.Context) ([]*model.Permission, error)
+	FindPermissionsByCodes(ctx context.Context, codes []string) ([]model.Permission, error)
+	ExistsPermissionByCode(ctx context.Context, code string) (bool, error)
+
+	ReplaceUserRoles(ctx context.Context, user *model.User, roles []model.Role) error
+	FindPermissionCodesByUserID(ctx context.Context, userID uint) ([]string, error)
+	// CountActiveUsersByRole 锁定角色行后统计拥有该角色的 active 用户数，需在事务内调用
+	CountActiveUsersByRole(ctx context.Context, roleName string) (int64, error)
+}
+
+type rbacRepository struct {
+	db *gorm.DB
+}
+
+func NewRBACRepository(db *gorm.DB) RBACRepository {
+	return &rbacRepository{db: db}
+}
+
+func (r *rbacRepository) CreateRole(ctx context.Context, role *model.Role) error {
+	return translateError(r.db.WithContext(ctx).Omit("Permissions.*").Create(role).Error, "name")
+}
+
+func (r *rbacRepository) FindRoleByID(ctx context.Context, id uint) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return &role, err
+}
+
+func (r *rbacRepository) FindRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return &role, err
+}
+
+func (r *rbacRepository) FindRoles(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// FindRolesByNames 按名称批量查询，任一名称不存在则返回 ErrRoleNotFound
+func (r *rbacRepository) FindRolesByNames(ctx context.Context, names []string) ([]model.Role, error) {
+	var roles []model.Role
+	if len(names) == 0 {
+		return roles, nil
+	}
+	if err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	if len(roles) != len(uniqueStrings(names)) {
+		return nil, ErrRoleNotFound
+	}
+	return roles, nil
+}
+
+func (r *rbacRepository) UpdateRole(ctx context.Context, role *model.Role) error {
+	return r.db.WithContext(ctx).Omit("Permissions").Save(role).Error
+}
+
+func (r *rbacRepository) ReplaceRolePermissions(ctx context.Context, role *model.Role, permissions []model.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+}
+
+// DeleteRole 删除角色及其关联（role_permissions、user_roles）
+func (r *rbacRepository) DeleteRole(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.Role{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleNotFound
+		}
+		return nil
+	})
+}
+
+func (r *rbacRepository) ExistsRoleByName(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Role{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *rbacRepository) CreatePermission(ctx context.Context, permission *model.Permission) error {
+	return translateError(r.db.WithContext(ctx).Create(permission).Error, "code")
+}
+
+func (r *rbacRepository) FindPermissions(ctx context.Context) ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	err := r.db.WithContext(ctx).Order("code").Find(&permissions).Error
+	return permissions, err
+}
+
+// FindPermissionsByCodes 按权限码批量查询，任一权限码不存在则返回 ErrPermissionNotFound
+func (r *rbacRepository) FindPermissionsByCodes(ctx context.Context, codes []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if len(codes) == 0 {
+		return permissions, nil
+	}
+	if err := r.db.WithContext(ctx).Where("code IN ?", codes).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	if len(permissions) != len(uniqueStrings(codes)) {
+		return nil, ErrPermissionNotFound
+	}
+	return permissions, nil
+}
+
+func (r *rbacRepository) ExistsPermissionByCode(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Permission{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *rbacRepository) ReplaceUserRoles(ctx context.Context, user *model.User, roles []model.Role) error {
+	return r.db.WithContext(ctx).Model(user).Omit("Roles.*").Association("Roles").Replace(roles)
+}
+
+// FindPermissionCodesByUserID 查询用户通过角色获得的全部权限码
+func (r *rbacRepository) FindPermissionCodesByUserID(ctx context.Context, userID uint) ([]string, error) {
+	var codes []string
+	err := r.db.WithContext(ctx).Model(&model.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct().
+		Pluck("permissions.code", &codes).Error
+	return codes, err
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// CountActiveUsersByRole 统计拥有指定角色且状态为 active 的未删除用户数
+// 📌 先对角色行加 FOR UPDATE 锁，并发移除管理员的事务依次执行，不会同时通过"至少保留一个"的检查
+// 📌 SQLite 不支持行锁（语句被忽略），其写事务本身是串行的
+func (r *rbacRepository) CountActiveUsersByRole(ctx context.Context, roleName string) (int64, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", roleName).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.db.WithContext(ctx).Model(&model.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Where("user_roles.role_id = ? AND users.status = ?", role.ID, model.UserStatusActive).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,113 @@
+// internal/repository/session_repository.go - 会话数据访问层
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = apperr.SessionNotFound
+
+// SessionRepository 会话仓储接口
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id uint) (*model.Session, error)
+	FindByTokenHash(ctx context.Context, hash string) (*model.Session, error)
+	Update(ctx context.Context, session *model.Session) error
+	// Rotate 仅当会话仍持有 oldHash 且未吊销时，把 refresh token 换成 session 中的新哈希并记录旧哈希
+	// 📌 返回 false 表示 oldHash 已被其他请求轮换或会话已吊销
+	Rotate(ctx context.Context, session *model.Session, oldHash string) (bool, error)
+	// FindRotated 查询已被轮换掉的 refresh token 所属会话，不存在时返回 ErrSessionNotFound
+	FindRotated(ctx context.Context, hash string) (*model.RotatedRefreshToken, error)
+	Revoke(ctx context.Context, id uint) error
+	RevokeByUserID(ctx context.Context, userID uint) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uint) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, err
+}
+
+func (r *sessionRepository) FindByTokenHash(ctx context.Context, hash string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("refresh_token_hash = ?", hash).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, err
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+// Rotate 使用带条件的 UPDATE 轮换，RowsAffected 为 0 说明并发请求已抢先轮换
+// 📌 先查后改时两个并发请求会各自成功，同一 refresh token 被使用两次
+func (r *sessionRepository) Rotate(ctx context.Context, session *model.Session, oldHash string) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.Session{}).
+			Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, oldHash).
+			Updates(map[string]interface{}{
+				"refresh_token_hash": session.RefreshTokenHash,
+				"expires_at":         session.ExpiresAt,
+				"updated_at":         now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		rotated = true
+		return tx.Create(&model.RotatedRefreshToken{
+			SessionID: session.ID,
+			UserID:    session.UserID,
+			TokenHash: oldHash,
+			RotatedAt: now,
+		}).Error
+	})
+	return rotated, err
+}
+
+func (r *sessionRepository) FindRotated(ctx context.Context, hash string) (*model.RotatedRefreshToken, error) {
+	var rotated model.RotatedRefreshToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&rotated).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &rotated, err
+}
+
+// Revoke 吊销单个会话，已吊销的会话保持原吊销时间
+func (r *sessionRepository) Revoke(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUserID 吊销用户的全部有效会话
+func (r *sessionRepository) RevokeByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
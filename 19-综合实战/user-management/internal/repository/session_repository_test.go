@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-management/internal/model"
+	"user-management/internal/testutil"
+)
+
+func TestSessionRepositoryRotate(t *testing.T) {
+	repo := NewSessionRepository(testutil.NewDB(t))
+	ctx := context.Background()
+
+	session := &model.Session{UserID: 1, RefreshTokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		oldHash string
+		newHash string
+		want    bool
+	}{
+		{"轮换当前 token", "h1", "h2", true},
+		{"旧 token 再次轮换失败", "h1", "h3", false},
+		{"继续轮换新 token", "h2", "h4", true},
+	}
+	for _, tt := range tests {
+		next := *session
+		next.RefreshTokenHash = tt.newHash
+		rotated, err := repo.Rotate(ctx, &next, tt.oldHash)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rotated != tt.want {
+			t.Errorf("%s: rotated = %v, want %v", tt.name, rotated, tt.want)
+		}
+	}
+
+	current, err := repo.FindByID(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.RefreshTokenHash != "h4" {
+		t.Errorf("RefreshTokenHash = %q, want h4", current.RefreshTokenHash)
+	}
+
+	// 被轮换掉的 token 记录在案，再次出现即视为重放
+	for _, hash := range []string{"h1", "h2"} {
+		rotated, err := repo.FindRotated(ctx, hash)
+		if err != nil {
+			t.Fatalf("FindRotated(%s): %v", hash, err)
+		}
+		if rotated.SessionID != session.ID {
+			t.Errorf("FindRotated(%s).SessionID = %d, want %d", hash, rotated.SessionID, session.ID)
+		}
+	}
+	if _, err := repo.FindRotated(ctx, "h3"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("FindRotated(h3): err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSessionRepositoryRotateRevoked(t *testing.T) {
+	repo := NewSessionRepository(testutil.NewDB(t))
+	ctx := context.Background()
+
+	session := &model.Session{UserID: 1, RefreshTokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Revoke(ctx, session.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	next := *session
+	next.RefreshTokenHash = "h2"
+	rotated, err := repo.Rotate(ctx, &next, "h1")
+	if err != nil || rotated {
+		t.Fatalf("Rotate revoked session = (%v, %v), want (false, nil)", rotated, err)
+	}
+}
@@ -0,0 +1,61 @@
+// internal/repository/tx_manager.go - 事务管理
+//
+// 📌 服务层需要把多次仓储调用放进同一事务时使用 TxManager:
+//
+//	err := txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+//	    if err := repos.Users.Update(ctx, user); err != nil {
+//	        return err // 返回错误 → 回滚
+//	    }
+//	    return repos.Audit.Create(ctx, event)
+//	})
+//
+// 📌 回调中只能使用 repos 里的仓储，它们共享同一个事务连接
+// 📌 ctx 取消或超时时事务回滚
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories 绑定到同一数据库连接（或事务）的仓储集合
+type Repositories struct {
+	Users    UserRepository
+	Sessions SessionRepository
+	Tokens   UserTokenRepository
+	RBAC     RBACRepository
+	MFA      MFARepository
+	Audit    AuditRepository
+}
+
+// TxManager 事务管理器接口
+type TxManager interface {
+	// Transaction 在同一事务中执行 fn，fn 返回错误或 panic 时整体回滚
+	Transaction(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) Transaction(ctx context.Context, fn func(repos *Repositories) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+func newRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:    NewUserRepository(db),
+		Sessions: NewSessionRepository(db),
+		Tokens:   NewUserTokenRepository(db),
+		RBAC:     NewRBACRepository(db),
+		MFA:      NewMFARepository(db),
+		Audit:    NewAuditRepository(db),
+	}
+}
@@ -2,28 +2,38 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
+	"user-management/internal/apperr"
 	"user-management/internal/model"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrUserNotFound      = errors.New("用户不存在")
+	ErrUserNotFound      = apperr.UserNotFound
 	ErrUserAlreadyExists = errors.New("用户已存在")
 )
 
 // UserRepository 用户仓储接口
 type UserRepository interface {
-	Create(user *model.User) error
-	FindByID(id uint) (*model.User, error)
-	FindByUsername(username string) (*model.User, error)
-	FindByEmail(email string) (*model.User, error)
-	FindAll(offset, limit int) ([]*model.User, int64, error)
-	Update(user *model.User) error
-	Delete(id uint) error
-	ExistsByUsername(username string) bool
-	ExistsByEmail(email string) bool
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindAll(ctx context.Context, query *model.UserQuery) ([]*model.User, int64, error)
+	FindAfter(ctx context.Context, query *model.UserQuery, after *model.UserCursor, limit int) ([]*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	Delete(ctx context.Context, id uint) error
+	FindDeletedByID(ctx context.Context, id uint) (*model.User, error)
+	Restore(ctx context.Context, id uint) error
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// HasAny 是否存在任何用户（包括软删除的）
+	HasAny(ctx context.Context) (bool, error)
 }
 
 type userRepository struct {
@@ -34,67 +44,222 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+// Create 创建用户，同时写入 user_roles 关联（不会改动角色本身）
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return translateError(r.db.WithContext(ctx).Omit("Roles.*").Create(user).Error, "username", "email")
 }
 
-func (r *userRepository) FindByID(id uint) (*model.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, id).Error
+	err := r.db.WithContext(ctx).Preload("Roles.Permissions").First(&user, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
 	return &user, err
 }
 
-func (r *userRepository) FindByUsername(username string) (*model.User, error) {
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).Preload("Roles.Permissions").Where("username = ?", username).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
 	return &user, err
 }
 
-func (r *userRepository) FindByEmail(email string) (*model.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Preload("Roles.Permissions").Where("email = ?", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, ErrUserNotFound
 	}
 	return &user, err
 }
 
-func (r *userRepository) FindAll(offset, limit int) ([]*model.User, int64, error) {
+// userSortColumns 允许排序的字段白名单，防止通过 sort_by 注入 SQL
+var userSortColumns = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"email":      "email",
+	"created_at": "created_at",
+}
+
+// FindAll 按条件分页查询用户
+func (r *userRepository) FindAll(ctx context.Context, query *model.UserQuery) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
-	r.db.Model(&model.User{}).Count(&total)
-	err := r.db.Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error
+	if err := r.db.WithContext(ctx).Model(&model.User{}).Scopes(userFilter(query)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	err := r.db.WithContext(ctx).Preload("Roles").
+		Scopes(userFilter(query)).
+		Order(userOrder(query)).
+		Offset(offset).Limit(query.PageSize).
+		Find(&users).Error
 
 	return users, total, err
 }
 
-func (r *userRepository) Update(user *model.User) error {
-	return r.db.Save(user).Error
+// FindAfter 游标分页查询，按 (created_at, id) 排序，返回 after 之后的最多 limit 条
+func (r *userRepository) FindAfter(ctx context.Context, query *model.UserQuery, after *model.UserCursor, limit int) ([]*model.User, error) {
+	var users []*model.User
+
+	direction, op := "DESC", "<"
+	if query.SortOrder == "asc" {
+		direction, op = "ASC", ">"
+	}
+
+	db := r.db.WithContext(ctx).Preload("Roles").Scopes(userFilter(query))
+	if after != nil {
+		db = db.Where("created_at "+op+" ? OR (created_at = ? AND id "+op+" ?)",
+			after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	err := db.Order("created_at " + direction + ", id " + direction).
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+// userFilter 构造 WHERE 条件，Count 和 Find 共用
+func userFilter(query *model.UserQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch query.Deleted {
+		case "include":
+			db = db.Unscoped()
+		case "only":
+			db = db.Unscoped().Where("deleted_at IS NOT NULL")
+		}
+		if query.Keyword != "" {
+			pattern := "%" + escapeLike(query.Keyword) + "%"
+			db = db.Where("username LIKE ? ESCAPE '!' OR email LIKE ? ESCAPE '!'", pattern, pattern)
+		}
+		if query.Role != "" {
+			db = db.Where("id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+				Table("user_roles").
+				Select("user_roles.user_id").
+				Joins("JOIN roles ON roles.id = user_roles.role_id").
+				Where("roles.name = ?", query.Role))
+		}
+		if query.Status != "" {
+			db = db.Where("status = ?", query.Status)
+		}
+		if query.CreatedFrom != nil {
+			db = db.Where("created_at >= ?", *query.CreatedFrom)
+		}
+		if query.CreatedTo != nil {
+			// created_to 按天包含，取次日零点作为开区间上界
+			db = db.Where("created_at < ?", query.CreatedTo.AddDate(0, 0, 1))
+		}
+		return db
+	}
+}
+
+// userOrder 构造 ORDER BY，id 作为第二排序键保证结果稳定
+func userOrder(query *model.UserQuery) string {
+	column, ok := userSortColumns[query.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if query.SortOrder == "asc" {
+		direction = "ASC"
+	}
+	if column == "id" {
+		return "id " + direction
+	}
+	return column + " " + direction + ", id " + direction
+}
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// Update 保存用户字段，角色关联通过 RBACRepository 单独维护
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	return translateError(r.db.WithContext(ctx).Omit("Roles").Save(user).Error, "username", "email")
 }
 
-func (r *userRepository) Delete(id uint) error {
-	result := r.db.Delete(&model.User{}, id)
+// Delete 软删除用户，角色关联保留以便恢复
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&model.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return ErrUserNotFound
 	}
-	return result.Error
+	return nil
 }
 
-func (r *userRepository) ExistsByUsername(username string) bool {
+// FindDeletedByID 查询已软删除的用户
+func (r *userRepository) FindDeletedByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Unscoped().Preload("Roles").Where("deleted_at IS NOT NULL").First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return &user, err
+}
+
+// Restore 恢复软删除的用户
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return translateError(result.Error, "username", "email")
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// purgedUserTables 彻底清除用户时一并删除的关联表
+// 📌 user_mfa 保存 TOTP 密钥，mfa_recovery_codes / user_tokens 保存凭据哈希，不能残留
+var purgedUserTables = []string{"user_roles", "sessions", "rotated_refresh_tokens", "user_tokens", "user_mfa", "mfa_recovery_codes"}
+
+// PurgeDeletedBefore 彻底删除 cutoff 之前软删除的用户及其关联数据
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.User{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+
+		for _, table := range purgedUserTables {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id IN (?)", expired).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Delete(&model.User{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *userRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var count int64
-	r.db.Model(&model.User{}).Where("username = ?", username).Count(&count)
-	return count > 0
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
 }
 
-func (r *userRepository) ExistsByEmail(email string) bool {
+func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	r.db.Model(&model.User{}).Where("email = ?", email).Count(&count)
-	return count > 0
+	err := r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) HasAny(ctx context.Context) (bool, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
 }
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-management/internal/model"
+	"user-management/internal/testutil"
+)
+
+func createTestUser(t *testing.T, repo UserRepository, username, email string) *model.User {
+	t.Helper()
+	user := &model.User{Username: username, Email: email, Password: "x", Status: model.UserStatusActive}
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestUserRepositoryCreateDuplicate(t *testing.T) {
+	repo := NewUserRepository(testutil.NewDB(t))
+	createTestUser(t, repo, "tom", "tom@example.com")
+
+	tests := []struct {
+		name       string
+		username   string
+		email      string
+		wantColumn string
+	}{
+		{"用户名冲突", "tom", "other@example.com", "username"},
+		{"邮箱冲突", "jerry", "tom@example.com", "email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &model.User{Username: tt.username, Email: tt.email, Password: "x", Status: model.UserStatusActive}
+			err := repo.Create(context.Background(), user)
+
+			var dupErr *DuplicateKeyError
+			if !errors.As(err, &dupErr) {
+				t.Fatalf("err = %v, want *DuplicateKeyError", err)
+			}
+			if dupErr.Column != tt.wantColumn {
+				t.Errorf("Column = %q, want %q", dupErr.Column, tt.wantColumn)
+			}
+			if !errors.Is(err, ErrDuplicateKey) {
+				t.Error("errors.Is(err, ErrDuplicateKey) = false")
+			}
+		})
+	}
+}
+
+func TestUserRepositoryExists(t *testing.T) {
+	repo := NewUserRepository(testutil.NewDB(t))
+	ctx := context.Background()
+	user := createTestUser(t, repo, "tom", "tom@example.com")
+
+	tests := []struct {
+		name   string
+		exists func() (bool, error)
+		want   bool
+	}{
+		{"用户名存在", func() (bool, error) { return repo.ExistsByUsername(ctx, "tom") }, true},
+		{"用户名不存在", func() (bool, error) { return repo.ExistsByUsername(ctx, "jerry") }, false},
+		{"邮箱存在", func() (bool, error) { return repo.ExistsByEmail(ctx, "tom@example.com") }, true},
+		{"邮箱不存在", func() (bool, error) { return repo.ExistsByEmail(ctx, "jerry@example.com") }, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.exists()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 软删除后用户名/邮箱可被重新注册
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := repo.ExistsByUsername(ctx, "tom"); err != nil || exists {
+		t.Fatalf("ExistsByUsername after delete = (%v, %v), want (false, nil)", exists, err)
+	}
+	createTestUser(t, repo, "tom", "tom@example.com")
+}
+
+func TestUserRepositoryExistsPropagatesError(t *testing.T) {
+	db := testutil.NewDB(t)
+	repo := NewUserRepository(db)
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	if _, err := repo.ExistsByUsername(context.Background(), "tom"); err == nil {
+		t.Fatal("ExistsByUsername on closed db: want error")
+	}
+}
+
+func TestUserRepositoryPurgeDeletedBefore(t *testing.T) {
+	db := testutil.NewDB(t)
+	repo := NewUserRepository(db)
+	sessions := NewSessionRepository(db)
+	ctx := context.Background()
+
+	deleted := createTestUser(t, repo, "tom", "tom@example.com")
+	kept := createTestUser(t, repo, "jerry", "jerry@example.com")
+	for _, user := range []*model.User{deleted, kept} {
+		session := &model.Session{UserID: user.ID, RefreshTokenHash: user.Username, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := sessions.Create(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 保留期内不清除
+	purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Fatalf("PurgeDeletedBefore(past) = (%d, %v), want (0, nil)", purged, err)
+	}
+
+	purged, err = repo.PurgeDeletedBefore(ctx, time.Now().Add(time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDeletedBefore(future) = (%d, %v), want (1, nil)", purged, err)
+	}
+	if _, err := repo.FindDeletedByID(ctx, deleted.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("FindDeletedByID after purge: err = %v, want ErrUserNotFound", err)
+	}
+	if _, err := sessions.FindByTokenHash(ctx, deleted.Username); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("session of purged user: err = %v, want ErrSessionNotFound", err)
+	}
+	if _, err := sessions.FindByTokenHash(ctx, kept.Username); err != nil {
+		t.Errorf("session of kept user: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// internal/repository/user_token_repository.go - 一次性令牌数据访问层
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-management/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var ErrTokenNotFound = errors.New("令牌不存在")
+
+// UserTokenRepository 一次性令牌仓储接口
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	FindByHash(ctx context.Context, purpose, hash string) (*model.UserToken, error)
+	// MarkUsed 标记为已使用，令牌已被使用过时返回 ErrTokenNotFound（保证只能用一次）
+	MarkUsed(ctx context.Context, id uint) error
+	// InvalidateByUser 作废用户某一用途下全部未使用的令牌
+	InvalidateByUser(ctx context.Context, userID uint, purpose string) error
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *userTokenRepository) FindByHash(ctx context.Context, purpose, hash string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).Where("purpose = ? AND token_hash = ?", purpose, hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTokenNotFound
+	}
+	return &token, err
+}
+
+func (r *userTokenRepository) MarkUsed(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (r *userTokenRepository) InvalidateByUser(ctx context.Context, userID uint, purpose string) error {
+	return r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
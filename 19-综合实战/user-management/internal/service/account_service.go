@@ -0,0 +1,324 @@
+// internal/service/account_service.go - 账号自助服务（找回密码、邮箱验证）
+//
+// 📌 找回密码流程:
+//  1. POST /api/password/forgot: 生成一次性令牌，通过邮件发送重置链接
+//  2. POST /api/password/reset: 校验令牌，设置新密码，吊销全部会话
+//
+// 📌 邮箱验证流程:
+//  1. 注册或修改邮箱时发送验证邮件
+//  2. GET /api/verify-email?token=: 校验令牌，记录验证时间
+//  3. POST /api/verify-email/resend: 重新发送（有冷却时间和每日上限）
+//
+// 📌 安全要点:
+//   - 无论邮箱是否存在都返回成功，防止枚举注册邮箱
+//   - 发信频率按请求的邮箱限制，在查询用户之前检查，已注册与未注册的邮箱得到相同的限流响应
+//   - 令牌只存哈希、有过期时间、只能使用一次，重新申请会作废旧令牌
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/mailer"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+)
+
+var (
+	ErrInvalidResetToken       = apperr.InvalidResetToken
+	ErrInvalidVerifyToken      = apperr.InvalidVerifyToken
+	ErrVerificationRateLimited = apperr.VerificationRateLimited
+	ErrResetRateLimited        = apperr.ResetRateLimited
+)
+
+// EmailVerifier 发送邮箱验证邮件（供 UserService 在注册、修改邮箱时调用）
+type EmailVerifier interface {
+	SendVerification(ctx context.Context, user *model.User) error
+}
+
+// AccountService 账号自助服务接口
+type AccountService interface {
+	EmailVerifier
+	ForgotPassword(ctx context.Context, req *model.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *model.ResetPasswordRequest, actor *model.Actor) error
+	VerifyEmail(ctx context.Context, token string, actor *model.Actor) error
+	ResendVerification(ctx context.Context, req *model.ResendVerificationRequest) error
+}
+
+type accountService struct {
+	userRepo     repository.UserRepository
+	tokenRepo    repository.UserTokenRepository
+	txManager    repository.TxManager
+	attempts     repository.LoginAttemptRepository
+	loginGuard   LoginGuard
+	policy       *password.Policy
+	hasher       password.Hasher
+	mailer       mailer.Mailer
+	baseURL      string
+	resetConfig  *config.PasswordResetConfig
+	verifyConfig *config.EmailVerificationConfig
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.UserTokenRepository,
+	txManager repository.TxManager,
+	attempts repository.LoginAttemptRepository,
+	loginGuard LoginGuard,
+	policy *password.Policy,
+	hasher password.Hasher,
+	mailer mailer.Mailer,
+	baseURL string,
+	resetConfig *config.PasswordResetConfig,
+	verifyConfig *config.EmailVerificationConfig,
+) AccountService {
+	return &accountService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		txManager:    txManager,
+		attempts:     attempts,
+		loginGuard:   loginGuard,
+		policy:       policy,
+		hasher:       hasher,
+		mailer:       mailer,
+		baseURL:      baseURL,
+		resetConfig:  resetConfig,
+		verifyConfig: verifyConfig,
+	}
+}
+
+// ForgotPassword 发送密码重置邮件
+// 📌 邮箱不存在或账号不可用时静默返回成功；超过频率限制返回 LockoutError（带 Retry-After）
+func (s *accountService) ForgotPassword(ctx context.Context, req *model.ForgotPasswordRequest) error {
+	err := s.throttleMail(ctx, "reset", req.Email,
+		time.Duration(s.resetConfig.RequestCooldownSeconds)*time.Second, s.resetConfig.RequestMaxPerDay, ErrResetRateLimited)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status == model.UserStatusDisabled {
+		return nil
+	}
+
+	// 作废之前未使用的重置令牌，只有最新的链接有效
+	if err := s.tokenRepo.InvalidateByUser(ctx, user.ID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	ttl := time.Duration(s.resetConfig.TokenTTLMinutes) * time.Minute
+	token, err := s.issueToken(ctx, user.ID, model.TokenPurposePasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("%s，您好：\n\n我们收到了重置密码的请求，请在 %d 分钟内打开以下链接设置新密码：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件。",
+		user.Username, s.resetConfig.TokenTTLMinutes, link)
+
+	return s.mailer.Send(user.Email, "重置密码", body)
+}
+
+// ResetPassword 使用令牌重置密码
+func (s *accountService) ResetPassword(ctx context.Context, req *model.ResetPasswordRequest, actor *model.Actor) error {
+	token, err := s.tokenRepo.FindByHash(ctx, model.TokenPurposePasswordReset, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !token.IsUsable(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.policy.Validate(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		// 占用令牌，并发请求中只有一个能成功
+		if err := repos.Tokens.MarkUsed(ctx, token.ID); err != nil {
+			if errors.Is(err, repository.ErrTokenNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		// 让其他设备上的会话全部下线
+		if err := repos.Sessions.RevokeByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserPasswordReset, user)
+		event.ActorID, event.ActorName = user.ID, user.Username
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已证明邮箱所有权：解除登录锁定
+	return s.loginGuard.Unlock(ctx, user.Username)
+}
+
+// SendVerification 发送邮箱验证邮件，之前未使用的验证令牌全部作废
+func (s *accountService) SendVerification(ctx context.Context, user *model.User) error {
+	if err := s.tokenRepo.InvalidateByUser(ctx, user.ID, model.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	ttl := time.Duration(s.verifyConfig.TokenTTLHours) * time.Hour
+	token, err := s.issueToken(ctx, user.ID, model.TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/verify-email?token=%s", s.baseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("%s，您好：\n\n请在 %d 小时内打开以下链接完成邮箱验证：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件。",
+		user.Username, s.verifyConfig.TokenTTLHours, link)
+
+	return s.mailer.Send(user.Email, "验证邮箱", body)
+}
+
+// VerifyEmail 使用令牌完成邮箱验证
+// 📌 pending 状态（注册时要求先验证邮箱）的账号验证后激活为 active
+func (s *accountService) VerifyEmail(ctx context.Context, plain string, actor *model.Actor) error {
+	token, err := s.tokenRepo.FindByHash(ctx, model.TokenPurposeEmailVerification, hashToken(plain))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrInvalidVerifyToken
+		}
+		return err
+	}
+	if !token.IsUsable(time.Now()) {
+		return ErrInvalidVerifyToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidVerifyToken
+		}
+		return err
+	}
+
+	before := userSnapshot(user)
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if user.Status == model.UserStatusPending {
+		user.Status = model.UserStatusActive
+	}
+
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Tokens.MarkUsed(ctx, token.ID); err != nil {
+			if errors.Is(err, repository.ErrTokenNotFound) {
+				return ErrInvalidVerifyToken
+			}
+			return err
+		}
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserEmailVerify, user)
+		event.ActorID, event.ActorName = user.ID, user.Username
+		event.Changes = auditDiff(before, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+}
+
+// ResendVerification 重新发送验证邮件
+// 📌 邮箱不存在或已验证时静默返回成功；超过频率限制返回 LockoutError（带 Retry-After）
+func (s *accountService) ResendVerification(ctx context.Context, req *model.ResendVerificationRequest) error {
+	err := s.throttleMail(ctx, "verify", req.Email,
+		time.Duration(s.verifyConfig.ResendCooldownSeconds)*time.Second, s.verifyConfig.ResendMaxPerDay, ErrVerificationRateLimited)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.SendVerification(ctx, user)
+}
+
+// throttleMail 按请求的邮箱限制发信频率：冷却时间 + 24 小时上限
+// 📌 在查询用户之前执行，未注册的邮箱同样计数，响应不会暴露邮箱是否注册
+// 📌 计数与登录防护共用 login_attempts 表（原子累加，多实例共享），key 形如 "reset:tom@example.com"
+func (s *accountService) throttleMail(ctx context.Context, purpose, email string, cooldown time.Duration, maxPerDay int, limitErr error) error {
+	key := attemptKey(purpose+":", strings.ToLower(email))
+	now := time.Now()
+	attempt, err := s.attempts.Find(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempt != nil && now.Sub(attempt.LastFailedAt) <= 24*time.Hour {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			return &LockoutError{Err: limitErr, RetryAfter: attempt.LockedUntil.Sub(now)}
+		}
+		if maxPerDay > 0 && attempt.Failures >= maxPerDay {
+			return &LockoutError{Err: limitErr, RetryAfter: attempt.LastFailedAt.Add(24 * time.Hour).Sub(now)}
+		}
+	}
+
+	if _, err := s.attempts.Increment(ctx, key, now, now.Add(-24*time.Hour)); err != nil {
+		return err
+	}
+	if cooldown > 0 {
+		return s.attempts.Lock(ctx, key, now.Add(cooldown))
+	}
+	return nil
+}
+
+// issueToken 生成一次性令牌，返回明文（只出现在邮件中）
+func (s *accountService) issueToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	plain, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	token := &model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"user-management/internal/config"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+)
+
+// recordingMailer 记录发出的邮件
+type recordingMailer struct {
+	sent []string
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, body)
+	return nil
+}
+
+var resetLinkPattern = regexp.MustCompile(`reset-password\?token=(\S+)`)
+
+// lastToken 从最后一封邮件的链接中取出令牌
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail sent")
+	}
+	match := resetLinkPattern.FindStringSubmatch(m.sent[len(m.sent)-1])
+	if match == nil {
+		t.Fatalf("no reset link in mail: %q", m.sent[len(m.sent)-1])
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type accountFixture struct {
+	accounts AccountService
+	userRepo repository.UserRepository
+	sessions repository.SessionRepository
+	guard    LoginGuard
+	mailer   *recordingMailer
+	hasher   password.Hasher
+	user     *model.User
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	db := testutil.NewDB(t)
+	policy, err := password.NewPolicy(&config.PasswordPolicyConfig{MinLength: 8, MaxBytes: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &accountFixture{
+		userRepo: repository.NewUserRepository(db),
+		sessions: repository.NewSessionRepository(db),
+		mailer:   &recordingMailer{},
+		hasher:   testutil.Hasher(t),
+	}
+	attempts := repository.NewLoginAttemptRepository(db)
+	f.guard = NewLoginGuard(attempts, &testLoginProtection, NopAuthEvents{})
+	f.accounts = NewAccountService(f.userRepo, repository.NewUserTokenRepository(db), repository.NewTxManager(db), attempts, f.guard,
+		policy, f.hasher, f.mailer, "https://example.com",
+		&config.PasswordResetConfig{TokenTTLMinutes: 30, RequestMaxPerDay: 10},
+		&config.EmailVerificationConfig{TokenTTLHours: 24})
+
+	hashed, err := f.hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.user = &model.User{Username: "tom", Email: "tom@example.com", Password: hashed, Status: model.UserStatusActive}
+	if err := f.userRepo.Create(context.Background(), f.user); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *accountFixture) forgot(t *testing.T) string {
+	t.Helper()
+	if err := f.accounts.ForgotPassword(context.Background(), &model.ForgotPasswordRequest{Email: f.user.Email}); err != nil {
+		t.Fatal(err)
+	}
+	return f.mailer.lastToken(t)
+}
+
+func TestResetPasswordSingleUse(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx := context.Background()
+
+	// 重置前的会话和登录锁定
+	session := &model.Session{UserID: f.user.ID, RefreshTokenHash: "h"}
+	if err := f.sessions.Create(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < testLoginProtection.MaxFailures; i++ {
+		if err := f.guard.RecordFailure(ctx, f.user.Username, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	token := f.forgot(t)
+	const newPassword = "New@2024!pw"
+	if err := f.accounts.ResetPassword(ctx, &model.ResetPasswordRequest{Token: token, NewPassword: newPassword}, &model.Actor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := f.userRepo.FindByID(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mustVerify(t, f.hasher, newPassword, user.Password) {
+		t.Error("password not changed")
+	}
+	if s, err := f.sessions.FindByID(ctx, session.ID); err != nil || s.RevokedAt == nil {
+		t.Errorf("session not revoked: %+v, %v", s, err)
+	}
+	if err := f.guard.Check(ctx, f.user.Username, ""); err != nil {
+		t.Errorf("lockout not cleared: %v", err)
+	}
+
+	// 同一令牌不能再次使用
+	err = f.accounts.ResetPassword(ctx, &model.ResetPasswordRequest{Token: token, NewPassword: "Other@2024!pw"}, &model.Actor{})
+	if !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("reused token: err = %v, want ErrInvalidResetToken", err)
+	}
+	if user, _ := f.userRepo.FindByID(ctx, f.user.ID); !mustVerify(t, f.hasher, newPassword, user.Password) {
+		t.Error("password changed by reused token")
+	}
+}
+
+func TestResetPasswordInvalidTokens(t *testing.T) {
+	f := newAccountFixture(t)
+	ctx := context.Background()
+
+	first := f.forgot(t)
+	second := f.forgot(t)
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantErr  error
+	}{
+		{"未知令牌", "unknown", "New@2024!pw", ErrInvalidResetToken},
+		{"新链接发出后旧令牌失效", first, "New@2024!pw", ErrInvalidResetToken},
+		{"新密码不符合策略，令牌不消耗", second, "short", password.ErrPolicyViolation},
+		{"最新令牌可用", second, "New@2024!pw", nil},
+	}
+	for _, tt := range tests {
+		err := f.accounts.ResetPassword(ctx, &model.ResetPasswordRequest{Token: tt.token, NewPassword: tt.password}, &model.Actor{})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// 未注册的邮箱静默成功，不发邮件
+	sent := len(f.mailer.sent)
+	if err := f.accounts.ForgotPassword(ctx, &model.ForgotPasswordRequest{Email: "ghost@example.com"}); err != nil {
+		t.Fatalf("unknown email: %v", err)
+	}
+	if len(f.mailer.sent) != sent {
+		t.Error("mail sent to unknown email")
+	}
+}
+
+func mustVerify(t *testing.T, h password.Hasher, pw, encoded string) bool {
+	t.Helper()
+	ok, err := h.Verify(pw, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
@@ -0,0 +1,215 @@
+// internal/service/admin_service.go - 管理员账号管理（命令行与启动引导）
+//
+// 📌 不内置默认账号，管理员只能通过以下方式产生:
+//   - server admin create/promote/demote: 运维通过命令行管理管理员
+//   - bootstrap_admin 配置: 空库（users 表没有任何记录）首次启动时创建，首次登录必须修改密码
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+)
+
+var (
+	ErrLastAdmin           = apperr.LastAdmin
+	ErrBootstrapIncomplete = errors.New("bootstrap_admin 需要同时配置 email 和 password")
+)
+
+// AdminService 管理员账号管理接口
+type AdminService interface {
+	CreateAdmin(ctx context.Context, req *model.CreateAdminRequest, actor *model.Actor) (*model.UserResponse, error)
+	Promote(ctx context.Context, username string, actor *model.Actor) (*model.UserResponse, error)
+	Demote(ctx context.Context, username string, actor *model.Actor) (*model.UserResponse, error)
+	// Bootstrap 按配置创建初始管理员，未配置或库中已有用户时返回 nil
+	Bootstrap(ctx context.Context, cfg *config.BootstrapAdminConfig) (*model.UserResponse, error)
+}
+
+type adminService struct {
+	userRepo  repository.UserRepository
+	rbacRepo  repository.RBACRepository
+	txManager repository.TxManager
+	policy    *password.Policy
+	hasher    password.Hasher
+}
+
+func NewAdminService(
+	userRepo repository.UserRepository,
+	rbacRepo repository.RBACRepository,
+	txManager repository.TxManager,
+	policy *password.Policy,
+	hasher password.Hasher,
+) AdminService {
+	return &adminService{
+		userRepo:  userRepo,
+		rbacRepo:  rbacRepo,
+		txManager: txManager,
+		policy:    policy,
+		hasher:    hasher,
+	}
+}
+
+// CreateAdmin 创建管理员，邮箱视为已验证
+func (s *adminService) CreateAdmin(ctx context.Context, req *model.CreateAdminRequest, actor *model.Actor) (*model.UserResponse, error) {
+	if err := s.policy.Validate(req.Password, req.Username); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.rbacRepo.FindRolesByNames(ctx, []string{model.RoleAdmin, model.RoleUser})
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedAt := time.Now()
+	user := &model.User{
+		Username:           req.Username,
+		Email:              req.Email,
+		Password:           hashedPassword,
+		Status:             model.UserStatusActive,
+		EmailVerifiedAt:    &verifiedAt,
+		MustChangePassword: req.MustChangePassword,
+		Roles:              roles,
+	}
+
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := checkUserUnique(ctx, repos.Users, user.Username, user.Email); err != nil {
+			return err
+		}
+		if err := repos.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserRegister, user)
+		event.Changes = auditDiff(nil, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, translateUserConflict(err)
+	}
+	return user.ToResponse(), nil
+}
+
+// Promote 为用户添加 admin 角色
+func (s *adminService) Promote(ctx context.Context, username string, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if hasRole(user, model.RoleAdmin) {
+		return user.ToResponse(), nil
+	}
+
+	admin, err := s.rbacRepo.FindRoleByName(ctx, model.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return s.replaceRoles(ctx, user, append(user.Roles, *admin), actor)
+}
+
+// Demote 移除用户的 admin 角色，至少保留一个管理员
+func (s *adminService) Demote(ctx context.Context, username string, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !hasRole(user, model.RoleAdmin) {
+		return user.ToResponse(), nil
+	}
+
+	roles := make([]model.Role, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		if role.Name != model.RoleAdmin {
+			roles = append(roles, role)
+		}
+	}
+	return s.replaceRoles(ctx, user, roles, actor)
+}
+
+// Bootstrap 空库首次启动时按配置创建管理员，首次登录必须修改密码
+// 📌 只看 users 表是否为空（包括软删除的用户），不看管理员数量：
+// 否则管理员全部被删除或降级后，任何能改配置或重启服务的人都能用配置里的密码重新得到管理员
+// 📌 已有用户的库需要管理员时使用 server admin create/promote
+// 📌 多个实例同时首次启动时只有一个能创建成功，其余视为已创建
+func (s *adminService) Bootstrap(ctx context.Context, cfg *config.BootstrapAdminConfig) (*model.UserResponse, error) {
+	if cfg.Username == "" {
+		return nil, nil
+	}
+	exists, err := s.userRepo.HasAny(ctx)
+	if err != nil || exists {
+		return nil, err
+	}
+	if cfg.Email == "" || cfg.Password == "" {
+		return nil, ErrBootstrapIncomplete
+	}
+
+	admin, err := s.CreateAdmin(ctx, &model.CreateAdminRequest{
+		Username:           cfg.Username,
+		Email:              cfg.Email,
+		Password:           cfg.Password,
+		MustChangePassword: true,
+	}, nil)
+	if errors.Is(err, ErrUsernameExists) || errors.Is(err, ErrEmailExists) {
+		return nil, nil
+	}
+	return admin, err
+}
+
+func (s *adminService) replaceRoles(ctx context.Context, user *model.User, roles []model.Role, actor *model.Actor) (*model.UserResponse, error) {
+	before := userSnapshot(user)
+	err := s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if !containsRole(roles, model.RoleAdmin) {
+			if err := ensureNotLastAdmin(ctx, repos.RBAC, user); err != nil {
+				return err
+			}
+		}
+		if err := repos.RBAC.ReplaceUserRoles(ctx, user, roles); err != nil {
+			return err
+		}
+		user.Roles = roles
+		event := newAuditEvent(actor, model.AuditUserRolesAssign, user)
+		event.Changes = auditDiff(before, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// ensureNotLastAdmin 用户是唯一的 active 管理员时返回 ErrLastAdmin
+// 📌 移除 admin 角色、删除、禁用、锁定前在同一事务内调用，否则系统可能失去所有管理员
+// 📌 本身不是 active 管理员的用户不影响管理员数量，直接放行
+func ensureNotLastAdmin(ctx context.Context, rbac repository.RBACRepository, user *model.User) error {
+	if user.Status != model.UserStatusActive || !hasRole(user, model.RoleAdmin) {
+		return nil
+	}
+	count, err := rbac.CountActiveUsersByRole(ctx, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func hasRole(user *model.User, name string) bool {
+	return containsRole(user.Roles, name)
+}
+
+func containsRole(roles []model.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
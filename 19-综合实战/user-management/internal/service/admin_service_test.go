@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-management/internal/config"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+type adminFixture struct {
+	db     *gorm.DB
+	admins AdminService
+	rbac   RBACService
+	users  UserService
+}
+
+func newAdminFixture(t *testing.T) *adminFixture {
+	t.Helper()
+	db := testutil.NewDB(t)
+	userRepo := repository.NewUserRepository(db)
+	rbacRepo := repository.NewRBACRepository(db)
+	txManager := repository.NewTxManager(db)
+	policy, err := password.NewPolicy(&config.PasswordPolicyConfig{MinLength: 8, MaxBytes: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := testutil.Hasher(t)
+	guard := NewLoginGuard(repository.NewLoginAttemptRepository(db), &testLoginProtection, NopAuthEvents{})
+
+	f := &adminFixture{
+		db:     db,
+		admins: NewAdminService(userRepo, rbacRepo, txManager, policy, hasher),
+		rbac:   NewRBACService(rbacRepo, userRepo, txManager),
+		users: NewUserService(userRepo, rbacRepo, repository.NewAuditRepository(db), txManager, nil, guard,
+			policy, hasher, nil, nil, &config.UserConfig{}, &config.EmailVerificationConfig{}, NopAuthEvents{}),
+	}
+	if err := f.rbac.SeedDefaults(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *adminFixture) createAdmin(t *testing.T, username string) *model.UserResponse {
+	t.Helper()
+	user, err := f.admins.CreateAdmin(context.Background(), &model.CreateAdminRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: testPassword,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestLastAdminProtected(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(f *adminFixture, user *model.UserResponse) error
+	}{
+		{"降级", func(f *adminFixture, user *model.UserResponse) error {
+			_, err := f.admins.Demote(context.Background(), user.Username, nil)
+			return err
+		}},
+		{"重新分配角色", func(f *adminFixture, user *model.UserResponse) error {
+			_, err := f.rbac.AssignUserRoles(context.Background(), user.ID, &model.AssignRolesRequest{Roles: []string{model.RoleUser}}, nil)
+			return err
+		}},
+		{"删除", func(f *adminFixture, user *model.UserResponse) error {
+			return f.users.DeleteUser(context.Background(), user.ID, nil)
+		}},
+		{"禁用", func(f *adminFixture, user *model.UserResponse) error {
+			_, err := f.users.DisableUser(context.Background(), user.ID, nil)
+			return err
+		}},
+		{"锁定", func(f *adminFixture, user *model.UserResponse) error {
+			_, err := f.users.LockUser(context.Background(), user.ID, nil)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAdminFixture(t)
+			first := f.createAdmin(t, "alice")
+			second := f.createAdmin(t, "bob")
+
+			// 还有其他管理员时允许
+			if err := tt.remove(f, first); err != nil {
+				t.Fatalf("with another admin: %v", err)
+			}
+			// 剩下的是唯一的 active 管理员
+			if err := tt.remove(f, second); !errors.Is(err, ErrLastAdmin) {
+				t.Fatalf("last admin: err = %v, want ErrLastAdmin", err)
+			}
+		})
+	}
+}
+
+func TestLastAdminIgnoresInactiveAdmins(t *testing.T) {
+	f := newAdminFixture(t)
+	ctx := context.Background()
+	first := f.createAdmin(t, "alice")
+	second := f.createAdmin(t, "bob")
+
+	if _, err := f.users.DisableUser(ctx, first.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 被禁用的管理员不算数
+	if _, err := f.users.LockUser(ctx, second.ID, nil); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("LockUser: err = %v, want ErrLastAdmin", err)
+	}
+	// 非 active 的管理员本身可以被删除
+	if err := f.users.DeleteUser(ctx, first.ID, nil); err != nil {
+		t.Fatalf("DeleteUser(disabled admin): %v", err)
+	}
+}
+
+func TestUpdateAdminRoleKeepsBuiltinPermissions(t *testing.T) {
+	f := newAdminFixture(t)
+	ctx := context.Background()
+	admin, err := repository.NewRBACRepository(f.db).FindRoleByName(ctx, model.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := make([]string, 0, len(defaultPermissions))
+	for _, p := range defaultPermissions {
+		if p.Code != model.PermRoleManage {
+			codes = append(codes, p.Code)
+		}
+	}
+	_, err = f.rbac.UpdateRole(ctx, admin.ID, &model.UpdateRoleRequest{Description: "x", Permissions: codes}, nil)
+	if !errors.Is(err, ErrAdminPermissions) {
+		t.Fatalf("remove role:manage: err = %v, want ErrAdminPermissions", err)
+	}
+
+	codes = append(codes, model.PermRoleManage)
+	role, err := f.rbac.UpdateRole(ctx, admin.ID, &model.UpdateRoleRequest{Description: "超级管理员", Permissions: codes}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role.Description != "超级管理员" {
+		t.Errorf("Description = %q", role.Description)
+	}
+}
+
+func TestRoleChangesAudited(t *testing.T) {
+	f := newAdminFixture(t)
+	ctx := context.Background()
+	actor := &model.Actor{UserID: 1, Username: "alice"}
+
+	if _, err := f.rbac.CreatePermission(ctx, &model.CreatePermissionRequest{Code: "report:read"}, actor); err != nil {
+		t.Fatal(err)
+	}
+	role, err := f.rbac.CreateRole(ctx, &model.CreateRoleRequest{Name: "auditor", Permissions: []string{model.PermAuditRead}}, actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.rbac.UpdateRole(ctx, role.ID, &model.UpdateRoleRequest{Permissions: []string{"report:read"}}, actor); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.rbac.DeleteRole(ctx, role.ID, actor); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []model.AuditEvent
+	if err := f.db.Order("id").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ action, target string }{
+		{model.AuditPermissionCreate, "report:read"},
+		{model.AuditRoleCreate, "auditor"},
+		{model.AuditRoleUpdate, "auditor"},
+		{model.AuditRoleDelete, "auditor"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d audit events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		if events[i].Action != w.action || events[i].TargetName != w.target || events[i].ActorID != actor.UserID {
+			t.Errorf("event %d = %s/%s by %d, want %s/%s by %d", i, events[i].Action, events[i].TargetName, events[i].ActorID, w.action, w.target, actor.UserID)
+		}
+	}
+	if events[2].Changes == "" {
+		t.Error("role.update without changes")
+	}
+}
+
+func TestBootstrapOnlyOnEmptyDatabase(t *testing.T) {
+	cfg := &config.BootstrapAdminConfig{Username: "root", Email: "root@example.com", Password: testPassword}
+
+	t.Run("空库", func(t *testing.T) {
+		f := newAdminFixture(t)
+		admin, err := f.admins.Bootstrap(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if admin == nil || !admin.MustChangePassword {
+			t.Fatalf("Bootstrap = %+v, want admin with must_change_password", admin)
+		}
+		// 再次启动不重复创建
+		if admin, err := f.admins.Bootstrap(context.Background(), cfg); err != nil || admin != nil {
+			t.Fatalf("second Bootstrap = (%v, %v), want (nil, nil)", admin, err)
+		}
+	})
+
+	t.Run("管理员已全部删除", func(t *testing.T) {
+		f := newAdminFixture(t)
+		ctx := context.Background()
+		admin := f.createAdmin(t, "alice")
+		if _, err := f.admins.Demote(ctx, admin.Username, nil); !errors.Is(err, ErrLastAdmin) {
+			t.Fatalf("Demote: err = %v, want ErrLastAdmin", err)
+		}
+		if err := f.db.Exec("DELETE FROM user_roles").Error; err != nil {
+			t.Fatal(err)
+		}
+		if admin, err := f.admins.Bootstrap(ctx, cfg); err != nil || admin != nil {
+			t.Fatalf("Bootstrap = (%v, %v), want (nil, nil)", admin, err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// internal/service/audit_service.go - 审计日志服务
+//
+// 📌 审计事件由各业务服务在同一事务中写入（见 repository.TxManager）
+// 📌 业务变更回滚时审计记录一起回滚，不会出现"有变更没记录"
+// 📌 本服务只负责查询和导出
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+	"user-management/internal/model"
+	"user-management/internal/repository"
+)
+
+// AuditExportLimit 单次导出的最大记录数
+const AuditExportLimit = 10000
+
+// AuditService 审计日志服务接口
+type AuditService interface {
+	List(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, int64, error)
+	Export(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) List(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, int64, error) {
+	return s.repo.FindAll(ctx, query)
+}
+
+func (s *auditService) Export(ctx context.Context, query *model.AuditQuery) ([]*model.AuditEvent, error) {
+	return s.repo.FindForExport(ctx, query, AuditExportLimit)
+}
+
+// newAuditEvent 构造审计事件，actor 为 nil 表示系统任务
+func newAuditEvent(actor *model.Actor, action string, target *model.User) *model.AuditEvent {
+	event := &model.AuditEvent{Action: action, ActorName: "system"}
+	if actor != nil {
+		event.ActorID = actor.UserID
+		event.ActorName = actor.Username
+		event.IP = actor.IP
+		event.UserAgent = truncate(actor.UserAgent, 255)
+		event.RequestID = actor.RequestID
+	}
+	if target != nil {
+		event.TargetID = target.ID
+		event.TargetName = target.Username
+	}
+	return event
+}
+
+// auditDiff 计算字段变更，只保留前后不同的字段，返回 JSON
+// 📌 before 为 nil 表示新建，after 为 nil 表示删除
+func auditDiff(before, after map[string]interface{}) model.AuditChanges {
+	changes := make(map[string]map[string]interface{})
+	for key, value := range after {
+		old, ok := before[key]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changes[key] = map[string]interface{}{"before": old, "after": value}
+	}
+	for key, old := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = map[string]interface{}{"before": old, "after": nil}
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return model.AuditChanges(data)
+}
+
+// userSnapshot 用户的可审计字段（不含密码等敏感信息）
+func userSnapshot(user *model.User) map[string]interface{} {
+	roles := user.RoleNames()
+	sort.Strings(roles)
+	return map[string]interface{}{
+		"username":             user.Username,
+		"email":                user.Email,
+		"status":               user.Status,
+		"email_verified":       user.EmailVerifiedAt != nil,
+		"roles":                roles,
+		"must_change_password": user.MustChangePassword,
+	}
+}
+
+// truncate 按字符截断到最多 max 个字符（与 varchar(n) 的计数方式一致）
+// 📌 按字节截断可能切开多字节字符，产生的非法 UTF-8 会被 PostgreSQL 拒绝，导致整个事务失败
+// 📌 来自请求头的非法 UTF-8 字节同样替换掉
+func truncate(s string, max int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,27 @@
+// internal/service/events.go - 业务事件
+//
+// 📌 服务只上报事件，不关心统计方式；metrics 包实现该接口并转换为 Prometheus 指标
+package service
+
+// 锁定范围
+const (
+	LockoutScopeUser = "user"
+	LockoutScopeIP   = "ip"
+)
+
+// AuthEvents 认证相关业务事件
+type AuthEvents interface {
+	LoginSucceeded()
+	LoginFailed()
+	UserRegistered()
+	// LockoutTriggered 连续失败达到上限，scope 为 LockoutScopeUser 或 LockoutScopeIP
+	LockoutTriggered(scope string)
+}
+
+// NopAuthEvents 不统计任何事件
+type NopAuthEvents struct{}
+
+func (NopAuthEvents) LoginSucceeded()         {}
+func (NopAuthEvents) LoginFailed()            {}
+func (NopAuthEvents) UserRegistered()         {}
+func (NopAuthEvents) LockoutTriggered(string) {}
@@ -0,0 +1,166 @@
+// internal/service/login_guard.go - 登录防暴力破解
+//
+// 📌 同时按用户名和客户端 IP 统计失败次数:
+//   - 用户名: 防止针对单个账号猜密码，超限返回 423
+//   - IP: 防止同一来源撞库（尝试大量不同用户名），超限返回 429
+//
+// 📌 指数退避: 超过阈值后，每多失败一次锁定时长翻倍，直到上限
+// 📌 最后一次失败超过统计窗口后计数自动清零
+// 📌 计数在数据库中原子累加，并发的失败登录不会丢失计数
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/logging"
+	"user-management/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrLoginTemporarilyLocked = apperr.LoginTemporarilyLocked
+	ErrTooManyLoginAttempts   = apperr.TooManyLoginAttempts
+)
+
+// LockoutError 锁定/限流错误，携带需要等待的时间
+type LockoutError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LockoutError) Unwrap() error {
+	return e.Err
+}
+
+// LoginGuard 登录防护接口
+type LoginGuard interface {
+	// Check 登录前检查用户名和 IP 是否处于锁定期
+	Check(ctx context.Context, username, ip string) error
+	RecordFailure(ctx context.Context, username, ip string) error
+	RecordSuccess(ctx context.Context, username string) error
+	// Unlock 管理员手动解除用户名锁定
+	Unlock(ctx context.Context, username string) error
+}
+
+type loginGuard struct {
+	repo   repository.LoginAttemptRepository
+	config *config.LoginProtectionConfig
+	events AuthEvents
+	now    func() time.Time
+}
+
+func NewLoginGuard(repo repository.LoginAttemptRepository, cfg *config.LoginProtectionConfig, events AuthEvents) LoginGuard {
+	return &loginGuard{
+		repo:   repo,
+		config: cfg,
+		events: events,
+		now:    time.Now,
+	}
+}
+
+func (g *loginGuard) Check(ctx context.Context, username, ip string) error {
+	if err := g.checkKey(ctx, userKey(username), ErrLoginTemporarilyLocked); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return g.checkKey(ctx, ipKey(ip), ErrTooManyLoginAttempts)
+}
+
+func (g *loginGuard) RecordFailure(ctx context.Context, username, ip string) error {
+	if err := g.recordKey(ctx, LockoutScopeUser, userKey(username), g.config.MaxFailures); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return g.recordKey(ctx, LockoutScopeIP, ipKey(ip), g.config.IPMaxFailures)
+}
+
+// RecordSuccess 登录成功后清除用户名计数
+// 📌 IP 计数不清除，否则攻击者可以用自己的账号登录一次来重置
+func (g *loginGuard) RecordSuccess(ctx context.Context, username string) error {
+	return g.repo.Delete(ctx, userKey(username))
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, username string) error {
+	return g.repo.Delete(ctx, userKey(username))
+}
+
+func (g *loginGuard) checkKey(ctx context.Context, key string, lockErr error) error {
+	attempt, err := g.repo.Find(ctx, key)
+	if err != nil || attempt == nil || attempt.LockedUntil == nil {
+		return err
+	}
+	if remaining := attempt.LockedUntil.Sub(g.now()); remaining > 0 {
+		return &LockoutError{Err: lockErr, RetryAfter: remaining}
+	}
+	return nil
+}
+
+func (g *loginGuard) recordKey(ctx context.Context, scope, key string, maxFailures int) error {
+	now := g.now()
+	attempt, err := g.repo.Increment(ctx, key, now, now.Add(-g.window()))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < maxFailures {
+		return nil
+	}
+
+	lockedUntil := now.Add(g.lockoutDuration(attempt.Failures - maxFailures))
+	logging.FromContext(ctx).Warn("登录失败次数超限，已锁定",
+		zap.String("key", key),
+		zap.Int("failures", attempt.Failures),
+		zap.Time("locked_until", lockedUntil),
+	)
+	g.events.LockoutTriggered(scope)
+	return g.repo.Lock(ctx, key, lockedUntil)
+}
+
+// lockoutDuration 第 n 次超限的锁定时长: base * 2^n，不超过上限
+func (g *loginGuard) lockoutDuration(n int) time.Duration {
+	base := time.Duration(g.config.BaseLockoutSeconds) * time.Second
+	max := time.Duration(g.config.MaxLockoutMinutes) * time.Minute
+
+	d := base
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (g *loginGuard) window() time.Duration {
+	return time.Duration(g.config.FailureWindowMinutes) * time.Minute
+}
+
+// maxKeyLength 与 login_attempts.attempt_key 的列宽一致
+const maxKeyLength = 150
+
+func userKey(username string) string {
+	return attemptKey("user:", strings.ToLower(username))
+}
+
+func ipKey(ip string) string {
+	return attemptKey("ip:", ip)
+}
+
+// attemptKey 超出列宽的值改用 SHA-256 哈希，否则超长用户名会导致写入失败（500）
+func attemptKey(prefix, value string) string {
+	if len(prefix)+len(value) <= maxKeyLength {
+		return prefix + value
+	}
+	return prefix + "sha256:" + hashToken(value)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+)
+
+func newTestLoginGuard(t *testing.T, cfg *config.LoginProtectionConfig) (LoginGuard, *testutil.Clock) {
+	t.Helper()
+	clock := testutil.NewClock(time.Unix(1_800_000_000, 0))
+	guard := NewLoginGuard(repository.NewLoginAttemptRepository(testutil.NewDB(t)), cfg, NopAuthEvents{}).(*loginGuard)
+	guard.now = clock.Now
+	return guard, clock
+}
+
+// retryAfter 返回锁定剩余时间，未锁定返回 0
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	if err == nil {
+		return 0
+	}
+	var lockErr *LockoutError
+	if !errors.As(err, &lockErr) {
+		t.Fatalf("err = %v, want *LockoutError", err)
+	}
+	return lockErr.RetryAfter
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	guard, clock := newTestLoginGuard(t, &testLoginProtection)
+	ctx := context.Background()
+
+	// 每次失败后等待锁定结束再失败一次，锁定时长翻倍直到上限
+	tests := []struct {
+		name string
+		want time.Duration
+	}{
+		{"第 1 次", 0},
+		{"第 2 次", 0},
+		{"达到阈值", time.Minute},
+		{"超限 1 次", 2 * time.Minute},
+		{"超限 2 次", 4 * time.Minute},
+		{"超限 3 次", 8 * time.Minute},
+		{"超限 4 次封顶", 15 * time.Minute},
+		{"超限 5 次封顶", 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+			t.Fatal(err)
+		}
+		err := guard.Check(ctx, "tom", "")
+		if got := retryAfter(t, err); got != tt.want {
+			t.Fatalf("%s: RetryAfter = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.want > 0 && !errors.Is(err, ErrLoginTemporarilyLocked) {
+			t.Fatalf("%s: err = %v, want ErrLoginTemporarilyLocked", tt.name, err)
+		}
+		// 用户名不区分大小写
+		if got := retryAfter(t, guard.Check(ctx, "TOM", "")); got != tt.want {
+			t.Fatalf("%s: RetryAfter(TOM) = %v, want %v", tt.name, got, tt.want)
+		}
+		clock.Advance(tt.want)
+		if err := guard.Check(ctx, "tom", ""); err != nil {
+			t.Fatalf("%s: Check after lockout expired: %v", tt.name, err)
+		}
+	}
+}
+
+func TestLoginGuardWindowReset(t *testing.T) {
+	guard, clock := newTestLoginGuard(t, &testLoginProtection)
+	ctx := context.Background()
+	window := time.Duration(testLoginProtection.FailureWindowMinutes) * time.Minute
+
+	for i := 0; i < testLoginProtection.MaxFailures-1; i++ {
+		if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 窗口内的下一次失败触发锁定；窗口外则重新计数
+	clock.Advance(window + time.Second)
+	if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, "tom", ""); err != nil {
+		t.Fatalf("failure after window: err = %v, want nil", err)
+	}
+
+	// 锁定结束后窗口过期，退避从基础时长重新开始
+	for i := 0; i < testLoginProtection.MaxFailures-1; i++ {
+		if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := retryAfter(t, guard.Check(ctx, "tom", "")); got != time.Minute {
+		t.Fatalf("RetryAfter = %v, want 1m", got)
+	}
+	clock.Advance(window + time.Second)
+	for i := 0; i < testLoginProtection.MaxFailures; i++ {
+		if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := retryAfter(t, guard.Check(ctx, "tom", "")); got != time.Minute {
+		t.Fatalf("RetryAfter after window = %v, want 1m", got)
+	}
+}
+
+func TestLoginGuardScopes(t *testing.T) {
+	cfg := testLoginProtection
+	cfg.IPMaxFailures = 4
+	guard, _ := newTestLoginGuard(t, &cfg)
+	ctx := context.Background()
+
+	// 同一 IP 尝试不同用户名，用户名计数都不超限，IP 计数超限
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := guard.RecordFailure(ctx, name, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Check(ctx, "e", "10.0.0.1"); !errors.Is(err, ErrTooManyLoginAttempts) {
+		t.Fatalf("same IP: err = %v, want ErrTooManyLoginAttempts", err)
+	}
+	if err := guard.Check(ctx, "e", "10.0.0.2"); err != nil {
+		t.Fatalf("other IP: err = %v, want nil", err)
+	}
+
+	// 登录成功只清除用户名计数，IP 锁定仍然有效
+	if err := guard.RecordSuccess(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, "a", "10.0.0.1"); !errors.Is(err, ErrTooManyLoginAttempts) {
+		t.Fatalf("after success: err = %v, want ErrTooManyLoginAttempts", err)
+	}
+
+	// 管理员解锁
+	for i := 0; i < cfg.MaxFailures; i++ {
+		if err := guard.RecordFailure(ctx, "tom", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Check(ctx, "tom", ""); !errors.Is(err, ErrLoginTemporarilyLocked) {
+		t.Fatalf("before unlock: err = %v, want ErrLoginTemporarilyLocked", err)
+	}
+	if err := guard.Unlock(ctx, "tom"); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, "tom", ""); err != nil {
+		t.Fatalf("after unlock: err = %v, want nil", err)
+	}
+
+	// 超长用户名改用哈希作为键，不会写入失败
+	long := strings.Repeat("x", 500)
+	if err := guard.RecordFailure(ctx, long, ""); err != nil {
+		t.Fatalf("long username: %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+// internal/service/mfa_service.go - TOTP 两步验证服务
+//
+// 📌 开启流程:
+//  1. Setup: 生成密钥和恢复码，返回 otpauth:// 链接（此时尚未生效）
+//  2. Enable: 用户用 App 扫码后提交一次验证码确认，两步验证生效
+//
+// 📌 Setup 和 Enable 都要求再次输入密码，仅凭 access token 不能设置或替换验证器
+// 📌 密码、验证码错误与登录共用 LoginGuard 计数（同一用户名和 IP），锁定期内直接拒绝
+//
+// 📌 登录流程（开启后）:
+//  1. POST /api/login 密码正确 → 返回短期 mfa_pending token
+//  2. POST /api/login/mfa 提交 mfa_pending token + 验证码 → 签发正式 token
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/totp"
+)
+
+var (
+	ErrMFAAlreadyEnabled = apperr.MFAAlreadyEnabled
+	ErrMFANotEnabled     = apperr.MFANotEnabled
+	ErrInvalidMFACode    = apperr.InvalidMFACode
+	ErrMFAReauthFailed   = apperr.MFAReauthFailed
+)
+
+// MFAService 两步验证服务接口
+type MFAService interface {
+	// Setup 生成密钥和恢复码，需要提供当前密码
+	Setup(ctx context.Context, userID uint, currentPassword string, actor *model.Actor) (*model.MFASetupResponse, error)
+	// Enable 确认开启，需要提供当前密码和新验证器的验证码
+	Enable(ctx context.Context, userID uint, currentPassword, code string, actor *model.Actor) error
+	// Disable 用户自行关闭，需要提供验证码或恢复码
+	Disable(ctx context.Context, userID uint, code string, actor *model.Actor) error
+	// Reset 管理员重置（用户丢失验证器和恢复码时）
+	Reset(ctx context.Context, userID uint, actor *model.Actor) error
+	IsEnabled(ctx context.Context, userID uint) (bool, error)
+	// Verify 校验 TOTP 验证码或恢复码
+	Verify(ctx context.Context, userID uint, code string) error
+}
+
+type mfaService struct {
+	repo       repository.MFARepository
+	userRepo   repository.UserRepository
+	txManager  repository.TxManager
+	hasher     password.Hasher
+	loginGuard LoginGuard
+	config     *config.MFAConfig
+	clock      func() time.Time
+}
+
+// NewMFAService 创建两步验证服务
+// 📌 clock 为当前时间函数，生产环境传 time.Now，测试时可注入固定时钟
+func NewMFAService(repo repository.MFARepository, userRepo repository.UserRepository, txManager repository.TxManager, hasher password.Hasher, loginGuard LoginGuard, cfg *config.MFAConfig, clock func() time.Time) MFAService {
+	return &mfaService{
+		repo:       repo,
+		userRepo:   userRepo,
+		txManager:  txManager,
+		hasher:     hasher,
+		loginGuard: loginGuard,
+		config:     cfg,
+		clock:      clock,
+	}
+}
+
+// Setup 生成新的密钥和恢复码
+// 📌 未确认前可重复调用，每次都会覆盖旧密钥
+// 📌 密钥和恢复码在同一事务中写入，避免新密钥配上旧恢复码
+func (s *mfaService) Setup(ctx context.Context, userID uint, currentPassword string, actor *model.Actor) (*model.MFASetupResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.guarded(ctx, user, actor, func() error {
+		return s.verifyPassword(user, currentPassword)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrMFANotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := &model.UserMFA{UserID: userID, Secret: secret}
+	if existing != nil {
+		mfa.CreatedAt = existing.CreatedAt
+	}
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.MFA.Save(ctx, mfa); err != nil {
+			return err
+		}
+		return repos.MFA.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MFASetupResponse{
+		Secret:        secret,
+		OTPAuthURI:    totp.URI(s.config.Issuer, user.Username, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Enable 用验证码确认后开启两步验证
+func (s *mfaService) Enable(ctx context.Context, userID uint, currentPassword, code string, actor *model.Actor) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var mfa *model.UserMFA
+	err = s.guarded(ctx, user, actor, func() error {
+		if err := s.verifyPassword(user, currentPassword); err != nil {
+			return err
+		}
+		var err error
+		mfa, err = s.repo.FindByUserID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMFANotFound) {
+				return ErrMFANotEnabled
+			}
+			return err
+		}
+		if mfa.IsEnabled() {
+			return ErrMFAAlreadyEnabled
+		}
+		return s.verifyTOTP(ctx, mfa, code)
+	})
+	if err != nil {
+		return err
+	}
+
+	now := s.clock()
+	mfa.EnabledAt = &now
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.MFA.Save(ctx, mfa); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newAuditEvent(actor, model.AuditUserMFAEnable, user))
+	})
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID uint, code string, actor *model.Actor) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = s.guarded(ctx, user, actor, func() error {
+		return s.Verify(ctx, userID, code)
+	})
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, userID, model.AuditUserMFADisable, actor)
+}
+
+func (s *mfaService) Reset(ctx context.Context, userID uint, actor *model.Actor) error {
+	return s.remove(ctx, userID, model.AuditUserMFAReset, actor)
+}
+
+// remove 删除两步验证配置并记录审计事件
+func (s *mfaService) remove(ctx context.Context, userID uint, action string, actor *model.Actor) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.MFA.Delete(ctx, userID); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newAuditEvent(actor, action, user))
+	})
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID uint) (bool, error) {
+	mfa, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFANotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.IsEnabled(), nil
+}
+
+// Verify 6 位数字按 TOTP 校验，其他格式按恢复码校验
+func (s *mfaService) Verify(ctx context.Context, userID uint, code string) error {
+	mfa, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFANotFound) {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	if !mfa.IsEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, mfa, code)
+	}
+
+	if err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code)); err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyPassword 用当前密码再次确认身份
+// 📌 两步验证开启后 Setup/Enable 会直接拒绝，此时只有密码可用于确认身份
+func (s *mfaService) verifyPassword(user *model.User, currentPassword string) error {
+	if ok, err := s.hasher.Verify(currentPassword, user.Password); err != nil || !ok {
+		return ErrMFAReauthFailed
+	}
+	return nil
+}
+
+// guarded 在登录防护下执行凭据校验
+// 📌 锁定期内不做任何校验直接返回锁定错误；密码或验证码错误计入与登录相同的用户名/IP 计数，
+// 持有被盗 access token 的人不能借这些接口绕过登录锁定猜密码或验证码
+func (s *mfaService) guarded(ctx context.Context, user *model.User, actor *model.Actor, verify func() error) error {
+	var ip string
+	if actor != nil {
+		ip = actor.IP
+	}
+	if err := s.loginGuard.Check(ctx, user.Username, ip); err != nil {
+		return err
+	}
+
+	err := verify()
+	if errors.Is(err, ErrMFAReauthFailed) || errors.Is(err, ErrInvalidMFACode) {
+		if recordErr := s.loginGuard.RecordFailure(ctx, user.Username, ip); recordErr != nil {
+			return recordErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return s.loginGuard.RecordSuccess(ctx, user.Username)
+}
+
+// verifyTOTP 校验验证码并记录时间步，同一验证码不能重复使用
+func (s *mfaService) verifyTOTP(ctx context.Context, mfa *model.UserMFA, code string) error {
+	step, ok := totp.Validate(mfa.Secret, code, s.clock(), s.config.Skew)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	if err := s.repo.AdvanceStep(ctx, mfa.UserID, step); err != nil {
+		if errors.Is(err, repository.ErrMFAStepReused) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+	mfa.LastStep = step
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文（展示给用户）和哈希（入库）
+// 📌 格式 xxxxx-xxxxx，小写 Base32 字符，约 50 位熵
+func (s *mfaService) generateRecoveryCodes() ([]string, []string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, s.config.RecoveryCodeCount)
+	hashes := make([]string, s.config.RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode 忽略大小写和分隔符后计算哈希
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return hashToken(normalized)
+}
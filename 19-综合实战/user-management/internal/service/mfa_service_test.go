@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/model"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+	"user-management/internal/totp"
+)
+
+const testPassword = "Tom@2024!pw"
+
+var testLoginProtection = config.LoginProtectionConfig{
+	MaxFailures:          3,
+	IPMaxFailures:        100,
+	BaseLockoutSeconds:   60,
+	MaxLockoutMinutes:    15,
+	FailureWindowMinutes: 15,
+}
+
+func newTestMFAService(t *testing.T) (MFAService, *model.User, *testutil.Clock) {
+	t.Helper()
+	db := testutil.NewDB(t)
+	hasher := testutil.Hasher(t)
+	hashed, err := hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	user := &model.User{Username: "tom", Email: "tom@example.com", Password: hashed, Status: model.UserStatusActive}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := testutil.NewClock(time.Unix(1_800_000_000, 0))
+	cfg := &config.MFAConfig{Issuer: "test", Skew: 1, RecoveryCodeCount: 3}
+	guard := NewLoginGuard(repository.NewLoginAttemptRepository(db), &testLoginProtection, NopAuthEvents{})
+	svc := NewMFAService(repository.NewMFARepository(db), userRepo, repository.NewTxManager(db), hasher, guard, cfg, clock.Now)
+	return svc, user, clock
+}
+
+// enableMFA 完成 Setup + Enable，返回密钥和恢复码
+func enableMFA(t *testing.T, svc MFAService, user *model.User, clock *testutil.Clock) *model.MFASetupResponse {
+	t.Helper()
+	ctx := context.Background()
+	setup, err := svc.Setup(ctx, user.ID, testPassword, &model.Actor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(setup.Secret, clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Enable(ctx, user.ID, testPassword, code, &model.Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	return setup
+}
+
+func TestMFASetupRequiresPassword(t *testing.T) {
+	svc, user, _ := newTestMFAService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Setup(ctx, user.ID, "wrong", &model.Actor{}); !errors.Is(err, ErrMFAReauthFailed) {
+		t.Fatalf("Setup with wrong password: err = %v, want ErrMFAReauthFailed", err)
+	}
+
+	setup, err := svc.Setup(ctx, user.ID, testPassword, &model.Actor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setup.Secret == "" || len(setup.RecoveryCodes) != 3 {
+		t.Fatalf("unexpected setup response: %+v", setup)
+	}
+	if enabled, _ := svc.IsEnabled(ctx, user.ID); enabled {
+		t.Fatal("MFA enabled before confirmation")
+	}
+}
+
+func TestMFAEnable(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		offset   time.Duration // 验证码时间相对当前时钟的偏移
+		wantErr  error
+	}{
+		{"当前验证码", testPassword, 0, nil},
+		{"偏差内的上一个验证码", testPassword, -totp.Period * time.Second, nil},
+		{"超出偏差", testPassword, -2 * totp.Period * time.Second, ErrInvalidMFACode},
+		{"密码错误", "wrong", 0, ErrMFAReauthFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, user, clock := newTestMFAService(t)
+			ctx := context.Background()
+
+			setup, err := svc.Setup(ctx, user.ID, testPassword, &model.Actor{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, err := totp.Code(setup.Secret, clock.Now().Add(tt.offset))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svc.Enable(ctx, user.ID, tt.password, code, &model.Actor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Enable: err = %v, want %v", err, tt.wantErr)
+			}
+			enabled, err := svc.IsEnabled(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enabled != (tt.wantErr == nil) {
+				t.Errorf("IsEnabled = %v", enabled)
+			}
+		})
+	}
+}
+
+func TestMFASetupAfterEnable(t *testing.T) {
+	svc, user, clock := newTestMFAService(t)
+	enableMFA(t, svc, user, clock)
+
+	if _, err := svc.Setup(context.Background(), user.ID, testPassword, &model.Actor{}); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Fatalf("err = %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
+
+func TestMFAVerifyRejectsReplay(t *testing.T) {
+	svc, user, clock := newTestMFAService(t)
+	setup := enableMFA(t, svc, user, clock)
+	ctx := context.Background()
+
+	// Enable 已使用当前时间步的验证码
+	code, _ := totp.Code(setup.Secret, clock.Now())
+	if err := svc.Verify(ctx, user.ID, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("replayed code: err = %v, want ErrInvalidMFACode", err)
+	}
+
+	clock.Advance(totp.Period * time.Second)
+	code, _ = totp.Code(setup.Secret, clock.Now())
+	if err := svc.Verify(ctx, user.ID, code); err != nil {
+		t.Fatalf("next step code: %v", err)
+	}
+
+	// 已使用时间步之前的验证码即使在偏差内也拒绝
+	old, _ := totp.Code(setup.Secret, clock.Now().Add(-totp.Period*time.Second))
+	if err := svc.Verify(ctx, user.ID, old); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("older code: err = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	svc, user, clock := newTestMFAService(t)
+	setup := enableMFA(t, svc, user, clock)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"恢复码", setup.RecoveryCodes[0], nil},
+		{"重复使用", setup.RecoveryCodes[0], ErrInvalidMFACode},
+		{"忽略大小写和分隔符", "  " + strings.ToUpper(strings.ReplaceAll(setup.RecoveryCodes[1], "-", "")), nil},
+		{"不存在的恢复码", "aaaaa-bbbbb", ErrInvalidMFACode},
+	}
+	for _, tt := range tests {
+		if err := svc.Verify(ctx, user.ID, tt.code); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMFADisable(t *testing.T) {
+	svc, user, clock := newTestMFAService(t)
+	setup := enableMFA(t, svc, user, clock)
+	ctx := context.Background()
+
+	if err := svc.Disable(ctx, user.ID, "000000", &model.Actor{}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("Disable with wrong code: err = %v, want ErrInvalidMFACode", err)
+	}
+	if err := svc.Disable(ctx, user.ID, setup.RecoveryCodes[0], &model.Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Verify(ctx, user.ID, setup.RecoveryCodes[1]); !errors.Is(err, ErrMFANotEnabled) {
+		t.Fatalf("Verify after disable: err = %v, want ErrMFANotEnabled", err)
+	}
+}
+
+func TestMFAFailuresCountTowardsLockout(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(svc MFAService, user *model.User, actor *model.Actor) error
+	}{
+		{"Setup 密码错误", func(svc MFAService, user *model.User, actor *model.Actor) error {
+			_, err := svc.Setup(context.Background(), user.ID, "wrong", actor)
+			return err
+		}},
+		{"Enable 密码错误", func(svc MFAService, user *model.User, actor *model.Actor) error {
+			return svc.Enable(context.Background(), user.ID, "wrong", "000000", actor)
+		}},
+		{"Disable 验证码错误", func(svc MFAService, user *model.User, actor *model.Actor) error {
+			return svc.Disable(context.Background(), user.ID, "000000", actor)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, user, clock := newTestMFAService(t)
+			enableMFA(t, svc, user, clock)
+			actor := &model.Actor{IP: "10.0.0.1"}
+
+			for i := 0; i < testLoginProtection.MaxFailures; i++ {
+				if err := tt.fail(svc, user, actor); err == nil || errors.Is(err, ErrLoginTemporarilyLocked) {
+					t.Fatalf("attempt %d: err = %v, want credential error", i+1, err)
+				}
+			}
+
+			// 锁定期内即使凭据正确也直接拒绝
+			var lockErr *LockoutError
+			_, err := svc.Setup(context.Background(), user.ID, testPassword, actor)
+			if !errors.As(err, &lockErr) || !errors.Is(err, ErrLoginTemporarilyLocked) {
+				t.Fatalf("Setup while locked: err = %v, want ErrLoginTemporarilyLocked", err)
+			}
+			if lockErr.RetryAfter <= 0 {
+				t.Errorf("RetryAfter = %v, want > 0", lockErr.RetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+// internal/service/rbac_service.go - 角色权限业务逻辑
+//
+// 📌 权限在数据库中维护，授予新权限无需改代码
+// 📌 内置角色 admin/user 与内置权限在启动时自动补齐
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"user-management/internal/apperr"
+	"user-management/internal/model"
+	"user-management/internal/repository"
+)
+
+var (
+	ErrRoleExists       = apperr.RoleExists
+	ErrPermissionExists = apperr.PermissionExists
+	ErrBuiltinRole      = apperr.BuiltinRole
+	ErrAdminPermissions = apperr.AdminPermissions
+)
+
+// defaultPermissions 内置权限
+var defaultPermissions = []model.Permission{
+	{Code: model.PermUserRead, Description: "查看用户列表"},
+	{Code: model.PermUserDelete, Description: "删除用户（软删除）"},
+	{Code: model.PermUserRestore, Description: "恢复已删除用户"},
+	{Code: model.PermUserPurge, Description: "彻底清除已删除用户"},
+	{Code: model.PermUserStatus, Description: "禁用/启用用户"},
+	{Code: model.PermUserMFA, Description: "重置用户两步验证"},
+	{Code: model.PermRoleRead, Description: "查看角色与权限"},
+	{Code: model.PermRoleManage, Description: "管理角色、权限及用户角色分配"},
+	{Code: model.PermAuditRead, Description: "查看和导出审计日志"},
+	{Code: model.PermSystemLog, Description: "查看和调整运行时日志级别"},
+}
+
+// RBACService 角色权限服务接口
+type RBACService interface {
+	SeedDefaults(ctx context.Context) error
+	ListRoles(ctx context.Context) ([]*model.Role, error)
+	CreateRole(ctx context.Context, req *model.CreateRoleRequest, actor *model.Actor) (*model.Role, error)
+	UpdateRole(ctx context.Context, id uint, req *model.UpdateRoleRequest, actor *model.Actor) (*model.Role, error)
+	DeleteRole(ctx context.Context, id uint, actor *model.Actor) error
+	ListPermissions(ctx context.Context) ([]*model.Permission, error)
+	CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, actor *model.Actor) (*model.Permission, error)
+	AssignUserRoles(ctx context.Context, userID uint, req *model.AssignRolesRequest, actor *model.Actor) (*model.UserResponse, error)
+	GetUserPermissions(ctx context.Context, userID uint) ([]string, error)
+}
+
+type rbacService struct {
+	repo      repository.RBACRepository
+	userRepo  repository.UserRepository
+	txManager repository.TxManager
+}
+
+func NewRBACService(repo repository.RBACRepository, userRepo repository.UserRepository, txManager repository.TxManager) RBACService {
+	return &rbacService{
+		repo:      repo,
+		userRepo:  userRepo,
+		txManager: txManager,
+	}
+}
+
+// SeedDefaults 补齐内置权限和角色，admin 角色始终拥有全部内置权限
+func (s *rbacService) SeedDefaults(ctx context.Context) error {
+	codes := make([]string, 0, len(defaultPermissions))
+	for _, p := range defaultPermissions {
+		codes = append(codes, p.Code)
+		exists, err := s.repo.ExistsPermissionByCode(ctx, p.Code)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		perm := p
+		if err := s.repo.CreatePermission(ctx, &perm); err != nil {
+			return err
+		}
+	}
+
+	builtinRoles := []model.Role{
+		{Name: model.RoleUser, Description: "普通用户"},
+		{Name: model.RoleAdmin, Description: "管理员"},
+	}
+	for i := range builtinRoles {
+		exists, err := s.repo.ExistsRoleByName(ctx, builtinRoles[i].Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := s.repo.CreateRole(ctx, &builtinRoles[i]); err != nil {
+			return err
+		}
+	}
+	admin, err := s.repo.FindRoleByName(ctx, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	perms, err := s.repo.FindPermissionsByCodes(ctx, codes)
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceRolePermissions(ctx, admin, mergePermissions(admin.Permissions, perms))
+}
+
+func (s *rbacService) ListRoles(ctx context.Context) ([]*model.Role, error) {
+	return s.repo.FindRoles(ctx)
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, req *model.CreateRoleRequest, actor *model.Actor) (*model.Role, error) {
+	exists, err := s.repo.ExistsRoleByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrRoleExists
+	}
+
+	perms, err := s.repo.FindPermissionsByCodes(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: perms,
+	}
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.RBAC.CreateRole(ctx, role); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newRoleAuditEvent(actor, model.AuditRoleCreate, role, nil, roleSnapshot(role)))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrRoleExists
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole 覆盖式更新角色描述和权限
+// 📌 描述与权限在同一事务内修改，不会出现只改了一半的角色
+// 📌 admin 角色必须保留全部内置权限，否则可能再也无法管理角色（启动时也会自动补回）
+func (s *rbacService) UpdateRole(ctx context.Context, id uint, req *model.UpdateRoleRequest, actor *model.Actor) (*model.Role, error) {
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	perms, err := s.repo.FindPermissionsByCodes(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == model.RoleAdmin && !coversDefaultPermissions(perms) {
+		return nil, ErrAdminPermissions
+	}
+
+	before := roleSnapshot(role)
+	role.Description = req.Description
+	role.Permissions = perms
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.RBAC.UpdateRole(ctx, role); err != nil {
+			return err
+		}
+		if err := repos.RBAC.ReplaceRolePermissions(ctx, role, perms); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newRoleAuditEvent(actor, model.AuditRoleUpdate, role, before, roleSnapshot(role)))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindRoleByID(ctx, id)
+}
+
+func (s *rbacService) DeleteRole(ctx context.Context, id uint, actor *model.Actor) error {
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsBuiltin() {
+		return ErrBuiltinRole
+	}
+
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.RBAC.DeleteRole(ctx, id); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newRoleAuditEvent(actor, model.AuditRoleDelete, role, roleSnapshot(role), nil))
+	})
+}
+
+func (s *rbacService) ListPermissions(ctx context.Context) ([]*model.Permission, error) {
+	return s.repo.FindPermissions(ctx)
+}
+
+func (s *rbacService) CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, actor *model.Actor) (*model.Permission, error) {
+	exists, err := s.repo.ExistsPermissionByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrPermissionExists
+	}
+
+	perm := &model.Permission{
+		Code:        req.Code,
+		Description: req.Description,
+	}
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.RBAC.CreatePermission(ctx, perm); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditPermissionCreate, nil)
+		event.TargetID = perm.ID
+		event.TargetName = truncate(perm.Code, 50)
+		event.Changes = auditDiff(nil, map[string]interface{}{
+			"code":        perm.Code,
+			"description": perm.Description,
+		})
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			return nil, ErrPermissionExists
+		}
+		return nil, err
+	}
+	return perm, nil
+}
+
+// AssignUserRoles 覆盖式设置用户角色
+// 📌 新权限在 access token 刷新后生效（permission_source=db 时立即生效）
+func (s *rbacService) AssignUserRoles(ctx context.Context, userID uint, req *model.AssignRolesRequest, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.repo.FindRolesByNames(ctx, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	before := userSnapshot(user)
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if !containsRole(roles, model.RoleAdmin) {
+			if err := ensureNotLastAdmin(ctx, repos.RBAC, user); err != nil {
+				return err
+			}
+		}
+		if err := repos.RBAC.ReplaceUserRoles(ctx, user, roles); err != nil {
+			return err
+		}
+		user.Roles = roles
+		event := newAuditEvent(actor, model.AuditUserRolesAssign, user)
+		event.Changes = auditDiff(before, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToResponse(), nil
+}
+
+// GetUserPermissions 实时查询用户权限，供权限中间件使用
+func (s *rbacService) GetUserPermissions(ctx context.Context, userID uint) ([]string, error) {
+	return s.repo.FindPermissionCodesByUserID(ctx, userID)
+}
+
+// coversDefaultPermissions perms 是否包含全部内置权限
+func coversDefaultPermissions(perms []model.Permission) bool {
+	have := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		have[p.Code] = true
+	}
+	for _, p := range defaultPermissions {
+		if !have[p.Code] {
+			return false
+		}
+	}
+	return true
+}
+
+// newRoleAuditEvent 角色变更的审计事件，TargetID/TargetName 为角色
+func newRoleAuditEvent(actor *model.Actor, action string, role *model.Role, before, after map[string]interface{}) *model.AuditEvent {
+	event := newAuditEvent(actor, action, nil)
+	event.TargetID = role.ID
+	event.TargetName = truncate(role.Name, 50)
+	event.Changes = auditDiff(before, after)
+	return event
+}
+
+// roleSnapshot 角色的可审计字段，权限按权限码排序
+func roleSnapshot(role *model.Role) map[string]interface{} {
+	codes := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		codes = append(codes, p.Code)
+	}
+	sort.Strings(codes)
+	return map[string]interface{}{
+		"name":        role.Name,
+		"description": role.Description,
+		"permissions": codes,
+	}
+}
+
+func mergePermissions(current, extra []model.Permission) []model.Permission {
+	seen := make(map[uint]bool, len(current))
+	merged := make([]model.Permission, 0, len(current)+len(extra))
+	for _, p := range append(current, extra...) {
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,342 @@
+// internal/service/token_service.go - Token 与会话服务
+//
+// 📌 双 token 模式:
+//   - access token: 短期 JWT，携带 sid（会话 ID），每次请求校验会话是否被吊销
+//   - refresh token: 长期随机串，数据库只存哈希，每次刷新都会轮换，旧 token 重复使用时吊销整个会话
+//
+// 📌 mfa_pending token: 密码正确但尚未完成两步验证时签发，只能用于 POST /api/login/mfa
+// 📌 两类 token 使用同一密钥签名，靠头部 typ 与 aud 区分；通过 JWKS 校验的下游服务必须检查
+// typ = at+jwt 且 aud 包含 AudienceAccess，否则只完成密码验证的 mfa_pending token 也会被接受
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/jwtkeys"
+	"user-management/internal/logging"
+	"user-management/internal/model"
+	"user-management/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidToken        = apperr.InvalidToken
+	ErrInvalidRefreshToken = apperr.InvalidRefreshToken
+)
+
+// token 类型（typ 声明）
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeMFAPending = "mfa_pending"
+)
+
+// JOSE 头部 typ（access token 遵循 RFC 9068）
+const (
+	HeaderTypeAccess     = "at+jwt"
+	HeaderTypeMFAPending = "mfa-pending+jwt"
+)
+
+// 每种 token 的受众（aud 声明）
+const (
+	AudienceAccess     = "api"
+	AudienceMFAPending = "login-mfa"
+)
+
+// Claims JWT 声明
+type Claims struct {
+	TokenType   string   `json:"typ"`
+	UserID      uint     `json:"user_id"`
+	Username    string   `json:"username"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	SessionID   uint     `json:"sid"`
+	// PasswordChangeRequired 为 true 时只能访问修改密码等少数接口，改密后刷新 token 即解除
+	PasswordChangeRequired bool `json:"pwd_change,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenService Token 服务接口
+type TokenService interface {
+	// IssueTokens sessions 由调用方传入，可以是事务内的仓储，与审计事件在同一事务中写入
+	IssueTokens(ctx context.Context, sessions repository.SessionRepository, user *model.User) (*model.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.LoginResponse, error)
+	// ParseAccessToken token 无效、会话不存在或已吊销时返回 ErrInvalidToken，其他错误为查询会话失败
+	ParseAccessToken(ctx context.Context, tokenString string) (*Claims, error)
+	// IssueMFAPendingToken 签发两步验证待完成 token（不创建会话）
+	IssueMFAPendingToken(user *model.User) (*model.LoginResponse, error)
+	ParseMFAPendingToken(tokenString string) (*Claims, error)
+	RevokeSession(ctx context.Context, sessionID uint) error
+	RevokeUserSessions(ctx context.Context, userID uint) error
+}
+
+type tokenService struct {
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	keySet      *jwtkeys.KeySet
+	jwtConfig   *config.JWTConfig
+}
+
+func NewTokenService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository, keySet *jwtkeys.KeySet, jwtConfig *config.JWTConfig) TokenService {
+	return &tokenService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		keySet:      keySet,
+		jwtConfig:   jwtConfig,
+	}
+}
+
+// IssueTokens 创建新会话并签发 access/refresh token
+func (s *tokenService) IssueTokens(ctx context.Context, sessions repository.SessionRepository, user *model.User) (*model.LoginResponse, error) {
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(s.refreshTTL()),
+	}
+	if err := sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(user, session, refreshToken)
+}
+
+// Refresh 使用 refresh token 换取新的 token 对
+// 📌 refresh token 每次使用后立即轮换，旧 token 失效
+// 📌 已轮换的旧 token 再次出现（重放或并发重复使用）视为泄露，吊销整个会话
+func (s *tokenService) Refresh(ctx context.Context, refreshToken string) (*model.LoginResponse, error) {
+	oldHash := hashToken(refreshToken)
+	session, err := s.sessionRepo.FindByTokenHash(ctx, oldHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, s.detectReuse(ctx, oldHash)
+		}
+		return nil, err
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = hashToken(newRefreshToken)
+	session.ExpiresAt = time.Now().Add(s.refreshTTL())
+	rotated, err := s.sessionRepo.Rotate(ctx, session, oldHash)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 并发请求已抢先使用同一个 refresh token
+		return nil, s.detectReuse(ctx, oldHash)
+	}
+
+	return s.buildResponse(user, session, newRefreshToken)
+}
+
+// detectReuse 未找到有效会话的 refresh token: 若是已轮换掉的旧 token 则吊销其会话，始终返回 ErrInvalidRefreshToken
+func (s *tokenService) detectReuse(ctx context.Context, hash string) error {
+	rotated, err := s.sessionRepo.FindRotated(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	logging.FromContext(ctx).Warn("检测到已轮换的 refresh token 被重复使用，吊销会话",
+		zap.Uint("session_id", rotated.SessionID),
+		zap.Uint("user_id", rotated.UserID),
+	)
+	if err := s.sessionRepo.Revoke(ctx, rotated.SessionID); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+// ParseAccessToken 校验 access token，并确认其会话仍然有效
+func (s *tokenService) ParseAccessToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := s.parseToken(tokenString, TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (s *tokenService) IssueMFAPendingToken(user *model.User) (*model.LoginResponse, error) {
+	now := time.Now()
+	ttl := time.Duration(s.jwtConfig.MFAPendingMinutes) * time.Minute
+	claims := Claims{
+		TokenType: TokenTypeMFAPending,
+		UserID:    user.ID,
+		Username:  user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.keySet.Issuer(),
+			Audience:  jwt.ClaimStrings{AudienceMFAPending},
+		},
+	}
+
+	token, err := s.keySet.Sign(claims, HeaderTypeMFAPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int64(ttl.Seconds()),
+	}, nil
+}
+
+func (s *tokenService) ParseMFAPendingToken(tokenString string) (*Claims, error) {
+	return s.parseToken(tokenString, TokenTypeMFAPending)
+}
+
+// tokenKinds 每种 token 类型对应的头部 typ 与受众
+var tokenKinds = map[string]struct {
+	header   string
+	audience string
+}{
+	TokenTypeAccess:     {header: HeaderTypeAccess, audience: AudienceAccess},
+	TokenTypeMFAPending: {header: HeaderTypeMFAPending, audience: AudienceMFAPending},
+}
+
+// parseToken 校验签名、算法、签发者、过期时间、头部 typ、受众和 token 类型
+func (s *tokenService) parseToken(tokenString, tokenType string) (*Claims, error) {
+	kind := tokenKinds[tokenType]
+	token, err := s.keySet.Parse(tokenString, &Claims{}, kind.header)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.TokenType != tokenType || !hasAudience(claims.Audience, kind.audience) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func hasAudience(audience jwt.ClaimStrings, want string) bool {
+	for _, aud := range audience {
+		if aud == want {
+			return true
+		}
+	}
+	return false
+}
+
+// RevokeSession 吊销单个会话（退出登录）
+func (s *tokenService) RevokeSession(ctx context.Context, sessionID uint) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return s.sessionRepo.Update(ctx, session)
+}
+
+// RevokeUserSessions 吊销用户的全部会话（删除用户、强制下线）
+func (s *tokenService) RevokeUserSessions(ctx context.Context, userID uint) error {
+	return s.sessionRepo.RevokeByUserID(ctx, userID)
+}
+
+func (s *tokenService) buildResponse(user *model.User, session *model.Session, refreshToken string) (*model.LoginResponse, error) {
+	accessToken, err := s.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTTL().Seconds()),
+		User:         user.ToResponse(),
+	}, nil
+}
+
+func (s *tokenService) generateAccessToken(user *model.User, sessionID uint) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		TokenType:   TokenTypeAccess,
+		UserID:      user.ID,
+		Username:    user.Username,
+		Roles:       user.RoleNames(),
+		Permissions: user.PermissionCodes(),
+		SessionID:   sessionID,
+		// 📌 改密标记写入 token，中间件无需查库
+		PasswordChangeRequired: user.MustChangePassword,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.keySet.Issuer(),
+			Audience:  jwt.ClaimStrings{AudienceAccess},
+		},
+	}
+
+	return s.keySet.Sign(claims, HeaderTypeAccess)
+}
+
+func (s *tokenService) accessTTL() time.Duration {
+	return time.Duration(s.jwtConfig.AccessExpireMinutes) * time.Minute
+}
+
+func (s *tokenService) refreshTTL() time.Duration {
+	return time.Duration(s.jwtConfig.RefreshExpireHours) * time.Hour
+}
+
+// generateRandomToken 生成 32 字节的随机 token（URL 安全的 Base64）
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 计算 token 的 SHA-256 哈希
+// 📌 随机 token 熵足够高，不需要 bcrypt 这类慢哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/jwtkeys"
+	"user-management/internal/model"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type tokenFixture struct {
+	tokens   TokenService
+	sessions repository.SessionRepository
+	keySet   *jwtkeys.KeySet
+	user     *model.User
+}
+
+func newTokenFixture(t *testing.T) *tokenFixture {
+	t.Helper()
+	db := testutil.NewDB(t)
+	userRepo := repository.NewUserRepository(db)
+	user := &model.User{Username: "tom", Email: "tom@example.com", Password: "x", Status: model.UserStatusActive}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.JWTConfig{Secret: strings.Repeat("s", 32), AccessExpireMinutes: 15, RefreshExpireHours: 1, MFAPendingMinutes: 5}
+	keySet, err := jwtkeys.Load(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := repository.NewSessionRepository(db)
+	return &tokenFixture{
+		tokens:   NewTokenService(sessions, userRepo, keySet, cfg),
+		sessions: sessions,
+		keySet:   keySet,
+		user:     user,
+	}
+}
+
+func TestTokenTypesNotInterchangeable(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	login, err := f.tokens.IssueTokens(ctx, f.sessions, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := f.tokens.IssueMFAPendingToken(f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// forge 用同一密钥签发头部 typ 与 aud 不一致的 token
+	forge := func(tokenType, aud, typ string) string {
+		t.Helper()
+		claims, err := f.tokens.ParseAccessToken(ctx, login.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims.TokenType = tokenType
+		claims.Audience = jwt.ClaimStrings{aud}
+		token, err := f.keySet.Sign(claims, typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	if _, err := f.tokens.ParseAccessToken(ctx, login.Token); err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if _, err := f.tokens.ParseMFAPendingToken(pending.MFAToken); err != nil {
+		t.Fatalf("mfa_pending token: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		parse func() error
+	}{
+		{"mfa_pending 当作 access", func() error {
+			_, err := f.tokens.ParseAccessToken(ctx, pending.MFAToken)
+			return err
+		}},
+		{"access 当作 mfa_pending", func() error {
+			_, err := f.tokens.ParseMFAPendingToken(login.Token)
+			return err
+		}},
+		{"头部 typ 错误", func() error {
+			_, err := f.tokens.ParseAccessToken(ctx, forge(TokenTypeAccess, AudienceAccess, HeaderTypeMFAPending))
+			return err
+		}},
+		{"aud 错误", func() error {
+			_, err := f.tokens.ParseAccessToken(ctx, forge(TokenTypeAccess, AudienceMFAPending, HeaderTypeAccess))
+			return err
+		}},
+		{"声明 typ 错误", func() error {
+			_, err := f.tokens.ParseAccessToken(ctx, forge(TokenTypeMFAPending, AudienceAccess, HeaderTypeAccess))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.parse(); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", tt.name, err)
+		}
+	}
+}
+
+func TestParseAccessTokenRevokedSession(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	login, err := f.tokens.IssueTokens(ctx, f.sessions, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := f.tokens.ParseAccessToken(ctx, login.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.tokens.RevokeSession(ctx, claims.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.tokens.ParseAccessToken(ctx, login.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("revoked session: err = %v, want ErrInvalidToken", err)
+	}
+
+	// 过期的 access token
+	expired := *claims
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	token, err := f.keySet.Sign(&expired, HeaderTypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.tokens.ParseAccessToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	login, err := f.tokens.IssueTokens(ctx, f.sessions, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := f.tokens.Refresh(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.RefreshToken == login.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+	// 新 token 对继续可用，会话不变
+	if _, err := f.tokens.ParseAccessToken(ctx, refreshed.Token); err != nil {
+		t.Fatalf("new access token: %v", err)
+	}
+	again, err := f.tokens.Refresh(ctx, refreshed.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh with rotated token: %v", err)
+	}
+
+	if _, err := f.tokens.Refresh(ctx, "never-issued"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown refresh token: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	// 未知 token 不影响现有会话
+	if _, err := f.tokens.ParseAccessToken(ctx, again.Token); err != nil {
+		t.Fatalf("access token after unknown refresh token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	f := newTokenFixture(t)
+	ctx := context.Background()
+
+	login, err := f.tokens.IssueTokens(ctx, f.sessions, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := f.tokens.IssueTokens(ctx, f.sessions, f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := f.tokens.Refresh(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已轮换掉的旧 token 被重放
+	if _, err := f.tokens.Refresh(ctx, login.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("replayed refresh token: err = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// 整个会话被吊销: 轮换后的 refresh token 和 access token 同样失效
+	if _, err := f.tokens.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after reuse: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := f.tokens.ParseAccessToken(ctx, refreshed.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token after reuse: err = %v, want ErrInvalidToken", err)
+	}
+
+	// 同一用户的其他会话不受影响
+	if _, err := f.tokens.ParseAccessToken(ctx, other.Token); err != nil {
+		t.Errorf("other session: %v", err)
+	}
+	if _, err := f.tokens.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Errorf("other session refresh: %v", err)
+	}
+}
@@ -1,161 +1,356 @@
 // internal/service/user_service.go - 用户业务逻辑层
+//
+// 📌 会修改数据的操作通过 TxManager 在同一事务中写入审计事件
+// 📌 actor 为发起操作的用户及请求信息，由 handler 传入
 package service
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"time"
+	"user-management/internal/apperr"
 	"user-management/internal/config"
+	"user-management/internal/logging"
 	"user-management/internal/model"
+	"user-management/internal/password"
 	"user-management/internal/repository"
 
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("用户名或密码错误")
-	ErrUsernameExists     = errors.New("用户名已存在")
-	ErrEmailExists        = errors.New("邮箱已存在")
-	ErrWrongPassword      = errors.New("原密码错误")
+	ErrInvalidCredentials = apperr.InvalidCredentials
+	ErrUsernameExists     = apperr.UsernameExists
+	ErrEmailExists        = apperr.EmailExists
+	ErrWrongPassword      = apperr.WrongPassword
+	ErrCursorSortInvalid  = apperr.CursorSortInvalid
+	ErrAccountPending     = apperr.AccountPending
+	ErrAccountDisabled    = apperr.AccountDisabled
+	ErrAccountLocked      = apperr.AccountLocked
+	ErrEmailNotVerified   = apperr.EmailNotVerified
 )
 
-// Claims JWT 声明
-type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
-	jwt.RegisteredClaims
-}
-
 // UserService 用户服务接口
 type UserService interface {
-	Register(req *model.RegisterRequest) (*model.UserResponse, error)
-	Login(req *model.LoginRequest) (*model.LoginResponse, error)
-	GetProfile(userID uint) (*model.UserResponse, error)
-	UpdateProfile(userID uint, req *model.UpdateProfileRequest) (*model.UserResponse, error)
-	ChangePassword(userID uint, req *model.ChangePasswordRequest) error
-	GetUsers(page, pageSize int) ([]*model.UserResponse, int64, error)
-	DeleteUser(id uint) error
+	Register(ctx context.Context, req *model.RegisterRequest, actor *model.Actor) (*model.UserResponse, error)
+	Login(ctx context.Context, req *model.LoginRequest, actor *model.Actor) (*model.LoginResponse, error)
+	LoginMFA(ctx context.Context, req *model.MFALoginRequest, actor *model.Actor) (*model.LoginResponse, error)
+	GetProfile(ctx context.Context, userID uint) (*model.UserResponse, error)
+	UpdateProfile(ctx context.Context, userID uint, req *model.UpdateProfileRequest, actor *model.Actor) (*model.UserResponse, error)
+	ChangePassword(ctx context.Context, userID uint, req *model.ChangePasswordRequest, actor *model.Actor) error
+	GetUsers(ctx context.Context, query *model.UserQuery) ([]*model.UserResponse, int64, error)
+	GetUsersByCursor(ctx context.Context, query *model.UserQuery) ([]*model.UserResponse, string, error)
+	DeleteUser(ctx context.Context, id uint, actor *model.Actor) error
+	RestoreUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error)
+	DisableUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error)
+	// LockUser 因安全原因锁定账号（如疑似被盗），需管理员解锁
+	LockUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error)
+	EnableUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error)
+	UnlockUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error)
+	// PurgeDeletedUsers actor 为 nil 表示由定时任务发起
+	PurgeDeletedUsers(ctx context.Context, actor *model.Actor) (int64, error)
 }
 
 type userService struct {
-	repo      repository.UserRepository
-	jwtConfig *config.JWTConfig
+	repo         repository.UserRepository
+	rbacRepo     repository.RBACRepository
+	auditRepo    repository.AuditRepository
+	txManager    repository.TxManager
+	tokenService TokenService
+	loginGuard   LoginGuard
+	policy       *password.Policy
+	hasher       password.Hasher
+	verifier     EmailVerifier
+	mfa          MFAService
+	userConfig   *config.UserConfig
+	verifyConfig *config.EmailVerificationConfig
+	events       AuthEvents
+
+	dummyOnce sync.Once
+	dummyHash string
 }
 
-func NewUserService(repo repository.UserRepository, jwtConfig *config.JWTConfig) UserService {
+func NewUserService(repo repository.UserRepository, rbacRepo repository.RBACRepository, auditRepo repository.AuditRepository, txManager repository.TxManager, tokenService TokenService, loginGuard LoginGuard, policy *password.Policy, hasher password.Hasher, verifier EmailVerifier, mfa MFAService, userConfig *config.UserConfig, verifyConfig *config.EmailVerificationConfig, events AuthEvents) UserService {
 	return &userService{
-		repo:      repo,
-		jwtConfig: jwtConfig,
+		repo:         repo,
+		rbacRepo:     rbacRepo,
+		auditRepo:    auditRepo,
+		txManager:    txManager,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
+		policy:       policy,
+		hasher:       hasher,
+		verifier:     verifier,
+		mfa:          mfa,
+		userConfig:   userConfig,
+		verifyConfig: verifyConfig,
+		events:       events,
 	}
 }
 
-func (s *userService) Register(req *model.RegisterRequest) (*model.UserResponse, error) {
-	// 检查用户名是否存在
-	if s.repo.ExistsByUsername(req.Username) {
-		return nil, ErrUsernameExists
+// Register 注册
+// 📌 唯一性检查和创建在同一事务中
+// 📌 并发注册仍可能同时通过检查，由唯一索引兜底，冲突转换为 ErrUsernameExists / ErrEmailExists
+func (s *userService) Register(ctx context.Context, req *model.RegisterRequest, actor *model.Actor) (*model.UserResponse, error) {
+	// 校验密码策略
+	if err := s.policy.Validate(req.Password, req.Username); err != nil {
+		return nil, err
 	}
 
-	// 检查邮箱是否存在
-	if s.repo.ExistsByEmail(req.Email) {
-		return nil, ErrEmailExists
+	// 哈希密码（耗时操作放在事务外，避免长时间占用事务）
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
 	}
 
-	// 哈希密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	// 新用户默认分配 user 角色
+	role, err := s.rbacRepo.FindRoleByName(ctx, model.RoleUser)
 	if err != nil {
 		return nil, err
 	}
 
+	// 📌 要求验证邮箱后才能登录时，新账号为 pending，完成邮箱验证后自动激活
+	status := model.UserStatusActive
+	if s.verifyConfig.RequireBeforeLogin {
+		status = model.UserStatusPending
+	}
+
 	user := &model.User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
-		Role:     "user",
+		Password: hashedPassword,
+		Status:   status,
+		Roles:    []model.Role{*role},
+	}
+
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := checkUserUnique(ctx, repos.Users, user.Username, user.Email); err != nil {
+			return err
+		}
+		if err := repos.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserRegister, user)
+		event.ActorID, event.ActorName = user.ID, user.Username
+		event.Changes = auditDiff(nil, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, translateUserConflict(err)
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	s.events.UserRegistered()
+
+	// 发送验证邮件
+	if err := s.verifier.SendVerification(ctx, user); err != nil {
 		return nil, err
 	}
 
 	return user.ToResponse(), nil
 }
 
-func (s *userService) Login(req *model.LoginRequest) (*model.LoginResponse, error) {
-	user, err := s.repo.FindByUsername(req.Username)
+func (s *userService) Login(ctx context.Context, req *model.LoginRequest, actor *model.Actor) (*model.LoginResponse, error) {
+	// 锁定期内直接拒绝，不再校验密码
+	if err := s.loginGuard.Check(ctx, req.Username, actor.IP); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindByUsername(ctx, req.Username)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		// 📌 数据库故障不是登录失败，不计数，按服务器错误返回
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		// 📌 用户不存在时同样做一次哈希校验，响应时间不会暴露用户名是否存在
+		_, _ = s.hasher.Verify(req.Password, s.dummyPasswordHash(ctx))
+		// 📌 不存在的用户名同样计数，避免通过锁定行为枚举用户
+		return nil, s.loginFailed(ctx, req.Username, nil, actor)
 	}
 
 	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	if ok, err := s.hasher.Verify(req.Password, user.Password); err != nil || !ok {
+		return nil, s.loginFailed(ctx, req.Username, user, actor)
+	}
+	if err := s.loginGuard.RecordSuccess(ctx, req.Username); err != nil {
+		return nil, err
+	}
+
+	// 旧算法或低成本的哈希透明升级，失败不影响本次登录
+	if s.hasher.NeedsRehash(user.Password) {
+		if rehashed, err := s.hasher.Hash(req.Password); err == nil {
+			user.Password = rehashed
+			if err := s.repo.Update(ctx, user); err != nil {
+				logging.FromContext(ctx).Warn("密码哈希升级失败", zap.Uint("user_id", user.ID), zap.Error(err))
+			}
+		}
 	}
 
-	// 生成 JWT
-	token, err := s.generateToken(user)
+	// 📌 密码正确后再检查状态，避免向猜密码的人泄露账号状态
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	if s.verifyConfig.RequireBeforeLogin && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// 开启两步验证的用户先拿到 mfa_pending token，验证码通过后才签发正式 token
+	mfaEnabled, err := s.mfa.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		return s.tokenService.IssueMFAPendingToken(user)
+	}
+
+	return s.issueLoginTokens(ctx, user, actor)
+}
+
+// LoginMFA 两步登录第二步：校验验证码后签发正式 token
+// 📌 验证码错误同样计入登录失败次数，防止暴力猜测 6 位验证码
+func (s *userService) LoginMFA(ctx context.Context, req *model.MFALoginRequest, actor *model.Actor) (*model.LoginResponse, error) {
+	claims, err := s.tokenService.ParseMFAPendingToken(req.MFAToken)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.LoginResponse{
-		Token: token,
-		User:  user.ToResponse(),
-	}, nil
+	if err := s.loginGuard.Check(ctx, claims.Username, actor.IP); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfa.Verify(ctx, user.ID, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.loginFailed(ctx, user.Username, user, actor); !errors.Is(err, ErrInvalidCredentials) {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if err := s.loginGuard.RecordSuccess(ctx, user.Username); err != nil {
+		return nil, err
+	}
+
+	return s.issueLoginTokens(ctx, user, actor)
+}
+
+// issueLoginTokens 创建会话，签发 access/refresh token，并记录登录事件
+// 📌 会话与登录审计事件在同一事务中写入
+func (s *userService) issueLoginTokens(ctx context.Context, user *model.User, actor *model.Actor) (*model.LoginResponse, error) {
+	var resp *model.LoginResponse
+	err := s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		var err error
+		resp, err = s.tokenService.IssueTokens(ctx, repos.Sessions, user)
+		if err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserLogin, user)
+		event.ActorID, event.ActorName = user.ID, user.Username
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.LoginSucceeded()
+	return resp, nil
 }
 
-func (s *userService) GetProfile(userID uint) (*model.UserResponse, error) {
-	user, err := s.repo.FindByID(userID)
+func (s *userService) GetProfile(ctx context.Context, userID uint) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	return user.ToResponse(), nil
 }
 
-func (s *userService) UpdateProfile(userID uint, req *model.UpdateProfileRequest) (*model.UserResponse, error) {
-	user, err := s.repo.FindByID(userID)
+func (s *userService) UpdateProfile(ctx context.Context, userID uint, req *model.UpdateProfileRequest, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	before := userSnapshot(user)
 
+	emailChanged := false
 	if req.Email != "" && req.Email != user.Email {
-		if s.repo.ExistsByEmail(req.Email) {
+		exists, err := s.repo.ExistsByEmail(ctx, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
 			return nil, ErrEmailExists
 		}
+		// 📌 新邮箱需要重新验证
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
+		emailChanged = true
 	}
 
-	if err := s.repo.Update(user); err != nil {
-		return nil, err
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserProfileUpdate, user)
+		event.Changes = auditDiff(before, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+	if err != nil {
+		return nil, translateUserConflict(err)
+	}
+
+	if emailChanged {
+		if err := s.verifier.SendVerification(ctx, user); err != nil {
+			return nil, err
+		}
 	}
 
 	return user.ToResponse(), nil
 }
 
-func (s *userService) ChangePassword(userID uint, req *model.ChangePasswordRequest) error {
-	user, err := s.repo.FindByID(userID)
+func (s *userService) ChangePassword(ctx context.Context, userID uint, req *model.ChangePasswordRequest, actor *model.Actor) error {
+	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 
 	// 验证原密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+	if ok, err := s.hasher.Verify(req.OldPassword, user.Password); err != nil || !ok {
 		return ErrWrongPassword
 	}
 
+	// 校验新密码策略
+	if err := s.policy.Validate(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
 	// 哈希新密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
 	if err != nil {
 		return err
 	}
 
-	user.Password = string(hashedPassword)
-	return s.repo.Update(user)
+	user.Password = hashedPassword
+	user.MustChangePassword = false
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newAuditEvent(actor, model.AuditUserPasswordChange, user))
+	})
 }
 
-func (s *userService) GetUsers(page, pageSize int) ([]*model.UserResponse, int64, error) {
-	offset := (page - 1) * pageSize
-	users, total, err := s.repo.FindAll(offset, pageSize)
+func (s *userService) GetUsers(ctx context.Context, query *model.UserQuery) ([]*model.UserResponse, int64, error) {
+	users, total, err := s.repo.FindAll(ctx, query)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -168,22 +363,275 @@ func (s *userService) GetUsers(page, pageSize int) ([]*model.UserResponse, int64
 	return responses, total, nil
 }
 
-func (s *userService) DeleteUser(id uint) error {
-	return s.repo.Delete(id)
+// GetUsersByCursor 游标分页查询用户，返回下一页游标（没有更多数据时为空）
+func (s *userService) GetUsersByCursor(ctx context.Context, query *model.UserQuery) ([]*model.UserResponse, string, error) {
+	if query.SortBy != "" && query.SortBy != "created_at" {
+		return nil, "", ErrCursorSortInvalid
+	}
+
+	var after *model.UserCursor
+	if query.Cursor != "" {
+		cursor, err := model.DecodeUserCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := cursor.Match(query); err != nil {
+			return nil, "", err
+		}
+		after = cursor
+	}
+
+	// 多取一条用于判断是否还有下一页
+	users, err := s.repo.FindAfter(ctx, query, after, query.PageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(users) > query.PageSize {
+		users = users[:query.PageSize]
+		nextCursor = users[len(users)-1].CursorOf(query).Encode()
+	}
+
+	responses := make([]*model.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	return responses, nextCursor, nil
 }
 
-func (s *userService) generateToken(user *model.User) (string, error) {
-	claims := Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.jwtConfig.ExpireHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "user-management",
-		},
+func (s *userService) DeleteUser(ctx context.Context, id uint, actor *model.Actor) error {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtConfig.Secret))
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := ensureNotLastAdmin(ctx, repos.RBAC, user); err != nil {
+			return err
+		}
+		if err := repos.Users.Delete(ctx, id); err != nil {
+			return err
+		}
+		// 删除后立即吊销该用户的全部会话，已签发的 token 随之失效
+		if err := repos.Sessions.RevokeByUserID(ctx, id); err != nil {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserDelete, user)
+		event.Changes = auditDiff(userSnapshot(user), nil)
+		return repos.Audit.Create(ctx, event)
+	})
+}
+
+// RestoreUser 恢复软删除的用户
+// 📌 删除期间用户名/邮箱可能已被他人注册，此时拒绝恢复
+func (s *userService) RestoreUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if err := checkUserUnique(ctx, repos.Users, user.Username, user.Email); err != nil {
+			return err
+		}
+		if err := repos.Users.Restore(ctx, id); err != nil {
+			return err
+		}
+		return repos.Audit.Create(ctx, newAuditEvent(actor, model.AuditUserRestore, user))
+	})
+	if err != nil {
+		return nil, translateUserConflict(err)
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	return user.ToResponse(), nil
+}
+
+// DisableUser 禁用用户，并吊销其全部会话使已签发的 token 立即失效
+func (s *userService) DisableUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.changeStatus(ctx, user, model.UserStatusDisabled, model.AuditUserDisable, actor); err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// LockUser 锁定用户，并吊销其全部会话
+// 📌 与禁用的区别: locked 表示安全原因的临时处置，通过 unlock 恢复
+func (s *userService) LockUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.changeStatus(ctx, user, model.UserStatusLocked, model.AuditUserLock, actor); err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// EnableUser 启用用户（从 pending/disabled/locked 恢复为 active）
+func (s *userService) EnableUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.changeStatus(ctx, user, model.UserStatusActive, model.AuditUserEnable, actor); err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// UnlockUser 解除登录锁定（清除失败计数，locked 状态恢复为 active）
+func (s *userService) UnlockUser(ctx context.Context, id uint, actor *model.Actor) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginGuard.Unlock(ctx, user.Username); err != nil {
+		return nil, err
+	}
+
+	status := user.Status
+	if status == model.UserStatusLocked {
+		status = model.UserStatusActive
+	}
+	if err := s.changeStatus(ctx, user, status, model.AuditUserUnlock, actor); err != nil {
+		return nil, err
+	}
+	return user.ToResponse(), nil
+}
+
+// PurgeDeletedUsers 彻底清除超过保留期的软删除用户
+func (s *userService) PurgeDeletedUsers(ctx context.Context, actor *model.Actor) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -s.userConfig.DeletedRetentionDays)
+
+	var purged int64
+	err := s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		var err error
+		purged, err = repos.Users.PurgeDeletedBefore(ctx, cutoff)
+		if err != nil || purged == 0 {
+			return err
+		}
+		event := newAuditEvent(actor, model.AuditUserPurge, nil)
+		event.Changes = auditDiff(nil, map[string]interface{}{
+			"purged": purged,
+			"cutoff": cutoff.Format(time.RFC3339),
+		})
+		return repos.Audit.Create(ctx, event)
+	})
+	return purged, err
+}
+
+// changeStatus 修改账号状态并记录审计事件
+// 📌 禁用或锁定时同一事务内吊销全部会话，已签发的 token 立即失效
+// 📌 不能禁用或锁定最后一个 active 管理员
+func (s *userService) changeStatus(ctx context.Context, user *model.User, status, action string, actor *model.Actor) error {
+	before := userSnapshot(user)
+	deactivate := status == model.UserStatusDisabled || status == model.UserStatusLocked
+
+	return s.txManager.Transaction(ctx, func(repos *repository.Repositories) error {
+		if deactivate {
+			if err := ensureNotLastAdmin(ctx, repos.RBAC, user); err != nil {
+				return err
+			}
+		}
+		user.Status = status
+		if err := repos.Users.Update(ctx, user); err != nil {
+			return err
+		}
+		if deactivate {
+			if err := repos.Sessions.RevokeByUserID(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+		event := newAuditEvent(actor, action, user)
+		event.Changes = auditDiff(before, userSnapshot(user))
+		return repos.Audit.Create(ctx, event)
+	})
+}
+
+// dummyPasswordHash 用户不存在时用于校验的哈希，算法和参数与新密码相同，耗时与真实校验一致
+// 📌 首次使用时计算一次；计算失败返回空串，Verify 会立即返回格式错误（只影响耗时，不影响结果）
+func (s *userService) dummyPasswordHash(ctx context.Context) string {
+	s.dummyOnce.Do(func() {
+		hash, err := s.hasher.Hash("dummy-password-for-unknown-user")
+		if err != nil {
+			logging.FromContext(ctx).Warn("生成占位密码哈希失败", zap.Error(err))
+			return
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
+// loginFailed 记录失败次数和审计事件，返回统一的凭证错误
+// 📌 user 为 nil 表示用户名不存在，审计中仍记录尝试的用户名
+func (s *userService) loginFailed(ctx context.Context, username string, user *model.User, actor *model.Actor) error {
+	event := newAuditEvent(actor, model.AuditUserLoginFailed, user)
+	event.ActorName = truncate(username, 50)
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		return err
+	}
+
+	if err := s.loginGuard.RecordFailure(ctx, username, actor.IP); err != nil {
+		return err
+	}
+	s.events.LoginFailed()
+	return ErrInvalidCredentials
+}
+
+// checkUserUnique 用户名或邮箱已被未删除的用户占用时返回 ErrUsernameExists / ErrEmailExists
+func checkUserUnique(ctx context.Context, users repository.UserRepository, username, email string) error {
+	exists, err := users.ExistsByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrUsernameExists
+	}
+	exists, err = users.ExistsByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailExists
+	}
+	return nil
+}
+
+// translateUserConflict 将用户名/邮箱的唯一约束冲突转换为业务错误
+func translateUserConflict(err error) error {
+	var dupErr *repository.DuplicateKeyError
+	if errors.As(err, &dupErr) {
+		switch dupErr.Column {
+		case "username":
+			return ErrUsernameExists
+		case "email":
+			return ErrEmailExists
+		}
+	}
+	return err
+}
+
+// checkUserStatus 只有 active 状态的账号可以登录或刷新 token
+func checkUserStatus(user *model.User) error {
+	switch user.Status {
+	case model.UserStatusActive:
+		return nil
+	case model.UserStatusPending:
+		return ErrAccountPending
+	case model.UserStatusLocked:
+		return ErrAccountLocked
+	default:
+		return ErrAccountDisabled
+	}
 }
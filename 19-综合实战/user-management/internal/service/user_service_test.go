@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"user-management/internal/apperr"
+	"user-management/internal/config"
+	"user-management/internal/jwtkeys"
+	"user-management/internal/model"
+	"user-management/internal/password"
+	"user-management/internal/repository"
+	"user-management/internal/testutil"
+)
+
+// countingHasher 记录 Verify 调用次数
+type countingHasher struct {
+	password.Hasher
+	verifies int
+}
+
+func (h *countingHasher) Verify(pw, encoded string) (bool, error) {
+	h.verifies++
+	return h.Hasher.Verify(pw, encoded)
+}
+
+// failingUserRepo FindByUsername 固定返回数据库错误
+type failingUserRepo struct {
+	repository.UserRepository
+	err error
+}
+
+func (r *failingUserRepo) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	return nil, r.err
+}
+
+type loginFixture struct {
+	users    UserService
+	userRepo repository.UserRepository
+	hasher   *countingHasher
+	guard    LoginGuard
+}
+
+// newLoginFixture 登录测试用的完整 UserService，wrap 可替换传给服务的 UserRepository
+func newLoginFixture(t *testing.T, hasher password.Hasher, wrap func(repository.UserRepository) repository.UserRepository) *loginFixture {
+	t.Helper()
+	db := testutil.NewDB(t)
+	policy, err := password.NewPolicy(&config.PasswordPolicyConfig{MinLength: 8, MaxBytes: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtConfig := &config.JWTConfig{Secret: strings.Repeat("s", 32), AccessExpireMinutes: 15, RefreshExpireHours: 1, MFAPendingMinutes: 5}
+	keySet, err := jwtkeys.Load(jwtConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &loginFixture{userRepo: repository.NewUserRepository(db), hasher: &countingHasher{Hasher: hasher}}
+	userRepo := f.userRepo
+	if wrap != nil {
+		userRepo = wrap(userRepo)
+	}
+	txManager := repository.NewTxManager(db)
+	f.guard = NewLoginGuard(repository.NewLoginAttemptRepository(db), &testLoginProtection, NopAuthEvents{})
+	tokens := NewTokenService(repository.NewSessionRepository(db), f.userRepo, keySet, jwtConfig)
+	mfa := NewMFAService(repository.NewMFARepository(db), f.userRepo, txManager, f.hasher, f.guard, &config.MFAConfig{Issuer: "test", Skew: 1, RecoveryCodeCount: 3}, time.Now)
+	f.users = NewUserService(userRepo, repository.NewRBACRepository(db), repository.NewAuditRepository(db), txManager, tokens, f.guard,
+		policy, f.hasher, nil, mfa, &config.UserConfig{}, &config.EmailVerificationConfig{}, NopAuthEvents{})
+	return f
+}
+
+// createUser 用指定哈希器生成密码哈希并直接写库
+func (f *loginFixture) createUser(t *testing.T, username string, hasher password.Hasher) *model.User {
+	t.Helper()
+	hashed, err := hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: username, Email: username + "@example.com", Password: hashed, Status: model.UserStatusActive}
+	if err := f.userRepo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestLoginUnknownUser(t *testing.T) {
+	f := newLoginFixture(t, testutil.Hasher(t), nil)
+	ctx := context.Background()
+	actor := &model.Actor{IP: "10.0.0.1"}
+	req := &model.LoginRequest{Username: "ghost", Password: testPassword}
+
+	for i := 0; i < testLoginProtection.MaxFailures; i++ {
+		if _, err := f.users.Login(ctx, req, actor); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	// 📌 用户不存在时同样做哈希校验
+	if f.hasher.verifies != testLoginProtection.MaxFailures {
+		t.Errorf("Verify called %d times, want %d", f.hasher.verifies, testLoginProtection.MaxFailures)
+	}
+	// 不存在的用户名同样会被锁定
+	if _, err := f.users.Login(ctx, req, actor); !errors.Is(err, ErrLoginTemporarilyLocked) {
+		t.Fatalf("after %d failures: err = %v, want ErrLoginTemporarilyLocked", testLoginProtection.MaxFailures, err)
+	}
+}
+
+func TestLoginLookupErrorNotCounted(t *testing.T) {
+	dbErr := errors.New("database is locked")
+	f := newLoginFixture(t, testutil.Hasher(t), func(r repository.UserRepository) repository.UserRepository {
+		return &failingUserRepo{UserRepository: r, err: dbErr}
+	})
+	ctx := context.Background()
+	actor := &model.Actor{IP: "10.0.0.1"}
+	req := &model.LoginRequest{Username: "tom", Password: testPassword}
+
+	for i := 0; i < testLoginProtection.MaxFailures+1; i++ {
+		if _, err := f.users.Login(ctx, req, actor); !errors.Is(err, dbErr) {
+			t.Fatalf("attempt %d: err = %v, want database error", i+1, err)
+		}
+	}
+	if f.hasher.verifies != 0 {
+		t.Errorf("Verify called %d times, want 0", f.hasher.verifies)
+	}
+	if err := f.guard.Check(ctx, req.Username, actor.IP); err != nil {
+		t.Fatalf("Check after database errors: %v, want nil", err)
+	}
+}
+
+func TestLoginRehash(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PasswordHashConfig
+	}{
+		{"bcrypt 成本提高", config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 5}},
+		{"bcrypt 升级为 argon2id", config.PasswordHashConfig{Algorithm: "argon2id", Argon2MemoryKB: 64, Argon2Iterations: 1, Argon2Parallelism: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := password.NewHasher(&tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := newLoginFixture(t, hasher, nil)
+			ctx := context.Background()
+			user := f.createUser(t, "tom", testutil.Hasher(t))
+			oldHash := user.Password
+
+			// 密码错误不升级
+			if _, err := f.users.Login(ctx, &model.LoginRequest{Username: "tom", Password: "wrong"}, &model.Actor{}); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("wrong password: err = %v, want ErrInvalidCredentials", err)
+			}
+			if stored, _ := f.userRepo.FindByID(ctx, user.ID); stored.Password != oldHash {
+				t.Fatal("hash changed after failed login")
+			}
+
+			if _, err := f.users.Login(ctx, &model.LoginRequest{Username: "tom", Password: testPassword}, &model.Actor{}); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := f.userRepo.FindByID(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Password == oldHash || hasher.NeedsRehash(stored.Password) {
+				t.Fatalf("hash not upgraded: %q", stored.Password)
+			}
+			// 升级后的哈希可以继续登录
+			if _, err := f.users.Login(ctx, &model.LoginRequest{Username: "tom", Password: testPassword}, &model.Actor{}); err != nil {
+				t.Fatalf("login after rehash: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetUsersByCursor(t *testing.T) {
+	f := newAdminFixture(t)
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(f.db)
+	for _, name := range []string{"amy", "bob", "cat", "dan", "eve"} {
+		if err := userRepo.Create(ctx, &model.User{Username: name, Email: name + "@example.com", Password: "x", Status: model.UserStatusActive}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 逐页读取，不重复不遗漏
+	var seen []string
+	query := &model.UserQuery{PageSize: 2, Status: model.UserStatusActive}
+	for {
+		users, next, err := f.users.GetUsersByCursor(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range users {
+			seen = append(seen, u.Username)
+		}
+		if next == "" {
+			break
+		}
+		query = &model.UserQuery{PageSize: 2, Status: model.UserStatusActive, Cursor: next}
+	}
+	if len(seen) != 5 || seen[0] != "eve" || seen[4] != "amy" {
+		t.Fatalf("pages = %v, want eve..amy", seen)
+	}
+
+	_, cursor, err := f.users.GetUsersByCursor(ctx, &model.UserQuery{PageSize: 2, Keyword: "a"})
+	if err != nil || cursor == "" {
+		t.Fatalf("first page: cursor = %q, err = %v", cursor, err)
+	}
+	tests := []struct {
+		name    string
+		query   model.UserQuery
+		wantErr error
+	}{
+		{"条件相同", model.UserQuery{PageSize: 2, Keyword: "a", SortBy: "created_at", SortOrder: "desc", Deleted: "exclude"}, nil},
+		{"排序方向改变", model.UserQuery{PageSize: 2, Keyword: "a", SortOrder: "asc"}, apperr.InvalidParams},
+		{"关键字改变", model.UserQuery{PageSize: 2, Keyword: "b"}, apperr.InvalidParams},
+		{"增加筛选条件", model.UserQuery{PageSize: 2, Keyword: "a", Role: model.RoleAdmin}, apperr.InvalidParams},
+		{"包含已删除", model.UserQuery{PageSize: 2, Keyword: "a", Deleted: "include"}, apperr.InvalidParams},
+		{"排序键不支持", model.UserQuery{PageSize: 2, Keyword: "a", SortBy: "username"}, ErrCursorSortInvalid},
+	}
+	for _, tt := range tests {
+		tt.query.Cursor = cursor
+		if _, _, err := f.users.GetUsersByCursor(ctx, &tt.query); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// 不含排序信息的旧格式游标
+	legacy := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2026-01-01T00:00:00Z","i":1}`))
+	if _, _, err := f.users.GetUsersByCursor(ctx, &model.UserQuery{PageSize: 2, Cursor: legacy}); !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("legacy cursor: err = %v, want ErrInvalidCursor", err)
+	}
+}
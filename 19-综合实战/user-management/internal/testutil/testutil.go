@@ -0,0 +1,73 @@
+// internal/testutil/testutil.go - 测试辅助
+//
+// 📌 只在 _test.go 中导入，生产代码不依赖本包
+// 📌 数据库使用内存 SQLite 并执行全部迁移，CI 无需外部数据库
+package testutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"user-management/internal/config"
+	"user-management/internal/database"
+	"user-management/internal/migrate"
+	"user-management/internal/password"
+
+	"gorm.io/gorm"
+)
+
+// NewDB 创建执行过全部迁移的内存数据库，测试结束时自动关闭
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&config.DatabaseConfig{Driver: database.DriverSQLite, LogLevel: "silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// Hasher 低成本的 bcrypt 哈希器，避免测试耗时
+func Hasher(t testing.TB) password.Hasher {
+	t.Helper()
+	hasher, err := password.NewHasher(&config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
+
+// Clock 可手动拨动的时钟，Now 可作为 func() time.Time 注入
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
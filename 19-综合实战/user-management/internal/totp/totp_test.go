@@ -0,0 +1,106 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestCode RFC 6238 附录 B 测试向量（8 位验证码取后 6 位）
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", time.Unix(59, 0)); err != ErrInvalidSecret {
+		t.Fatalf("err = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	codeAtOffset := func(steps int) string {
+		code, err := Code(rfcSecret, now.Add(time.Duration(steps*Period)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantOK   bool
+		wantStep int64
+	}{
+		{"当前时间步", codeAtOffset(0), 1, true, step},
+		{"上一个时间步在偏差内", codeAtOffset(-1), 1, true, step - 1},
+		{"下一个时间步在偏差内", codeAtOffset(1), 1, true, step + 1},
+		{"超出偏差", codeAtOffset(-2), 1, false, 0},
+		{"不允许偏差", codeAtOffset(-1), 0, false, 0},
+		{"前后空白", " " + codeAtOffset(0) + " ", 0, true, step},
+		{"位数不对", "12345", 1, false, 0},
+		{"错误验证码", "000000", 0, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != SecretSize {
+		t.Errorf("key length = %d, want %d", len(key), SecretSize)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("user-management", "tom", rfcSecret)
+	for _, want := range []string{
+		"otpauth://totp/user-management:tom?",
+		"secret=" + rfcSecret,
+		"issuer=user-management",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("URI %q missing %q", uri, want)
+		}
+	}
+}